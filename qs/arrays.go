@@ -0,0 +1,118 @@
+package qs
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ArrayStyle determines how a key with multiple values is written when a QS
+// is converted to a string.
+type ArrayStyle int
+
+const (
+	// ArrayRepeat repeats the key for every value e.g. a=1&a=2.
+	ArrayRepeat ArrayStyle = iota
+	// ArrayBrackets appends empty brackets to the key e.g. a[]=1&a[]=2.
+	ArrayBrackets
+	// ArrayIndices appends the index of each value to the key
+	// e.g. a[0]=1&a[1]=2. Keys that also have indexed subkeys are written
+	// as with ArrayRepeat, so that the indices do not clash.
+	ArrayIndices
+	// ArrayComma joins the values with commas e.g. a=1,2. When encoding,
+	// commas within a value are escaped.
+	ArrayComma
+)
+
+// collapseArrays rewrites indexed subkeys below n into arrays. A subkey is an
+// index if it is a non-negative integer written without leading zeros that is
+// no greater than limit. Indexed subkeys without children of their own are
+// removed and their values are appended to the parent's values in index order.
+// Indexed subkeys with children are kept, but renumbered from zero in index
+// order so that sparse indices produce a dense array. The root node is never
+// treated as an array. If limit is negative, nothing is collapsed.
+func collapseArrays(n *node, limit int) {
+	if limit < 0 {
+		return
+	}
+
+	for _, k := range n.keys() {
+		collapseNode(n.Children[k], limit)
+	}
+}
+
+func collapseNode(n *node, limit int) {
+	type element struct {
+		index int
+		key   string
+	}
+
+	elements := make([]element, 0)
+	for _, k := range n.keys() {
+		collapseNode(n.Children[k], limit)
+
+		if i, ok := arrayIndex(k, limit); ok {
+			elements = append(elements, element{index: i, key: k})
+		}
+	}
+
+	if len(elements) == 0 {
+		return
+	}
+
+	sort.Slice(elements, func(i, j int) bool { return elements[i].index < elements[j].index })
+
+	isElement := make(map[string]bool, len(elements))
+	objects := make([]*node, 0)
+	for _, e := range elements {
+		isElement[e.key] = true
+
+		child := n.Children[e.key]
+		delete(n.Children, e.key)
+
+		if len(child.Children) == 0 {
+			n.Values = append(n.Values, child.Values...)
+			continue
+		}
+		objects = append(objects, child)
+	}
+
+	order := make([]string, 0, len(n.Order))
+	inserted := false
+	for _, k := range n.Order {
+		if !isElement[k] {
+			order = append(order, k)
+			continue
+		}
+		if inserted {
+			continue
+		}
+		inserted = true
+
+		for i, child := range objects {
+			child.Key = strconv.Itoa(i)
+			n.Children[child.Key] = child
+			order = append(order, child.Key)
+		}
+	}
+	n.Order = order
+}
+
+// arrayIndex reports whether key is an array index no greater than limit.
+func arrayIndex(key string, limit int) (int, bool) {
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, false
+	}
+
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+
+	i, err := strconv.Atoi(key)
+	if err != nil || i > limit {
+		return 0, false
+	}
+
+	return i, true
+}
@@ -0,0 +1,264 @@
+package qs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNew_Arrays(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  []Option
+		path  []string
+		want  []interface{}
+	}{
+		{
+			name:  "Indexed values",
+			query: "a[0]=x&a[1]=y",
+			path:  []string{"a"},
+			want:  []interface{}{"x", "y"},
+		},
+		{
+			name:  "Out of order indices",
+			query: "a[1]=y&a[0]=x",
+			path:  []string{"a"},
+			want:  []interface{}{"x", "y"},
+		},
+		{
+			name:  "Sparse indices",
+			query: "a[2]=y&a[0]=x&a[9]=z",
+			path:  []string{"a"},
+			want:  []interface{}{"x", "y", "z"},
+		},
+		{
+			name:  "Nested indices",
+			query: "a[b][0]=x&a[b][1]=y",
+			path:  []string{"a", "b"},
+			want:  []interface{}{"x", "y"},
+		},
+		{
+			name:  "Above limit",
+			query: "a[21]=x",
+			path:  []string{"a", "21"},
+			want:  []interface{}{"x"},
+		},
+		{
+			name:  "Custom limit",
+			query: "a[5]=x&a[6]=y",
+			opts:  []Option{ArrayLimit(5)},
+			path:  []string{"a", "6"},
+			want:  []interface{}{"y"},
+		},
+		{
+			name:  "Disabled",
+			query: "a[0]=x",
+			opts:  []Option{ArrayLimit(-1)},
+			path:  []string{"a", "0"},
+			want:  []interface{}{"x"},
+		},
+		{
+			name:  "Leading zero",
+			query: "a[01]=x",
+			path:  []string{"a", "01"},
+			want:  []interface{}{"x"},
+		},
+		{
+			name:  "Root keys",
+			query: "0=x",
+			path:  []string{"0"},
+			want:  []interface{}{"x"},
+		},
+		{
+			name:  "Objects are renumbered",
+			query: "a[7][id]=2&a[3][id]=1",
+			path:  []string{"a", "1", "id"},
+			want:  []interface{}{"2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.GetAll(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Arrays_GetStringSlice(t *testing.T) {
+	q, err := New("a[0]=x&a[1]=y")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	want := []string{"x", "y"}
+	if got := q.GetStringSlice("a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.GetStringSlice() = %v, want %v", got, want)
+	}
+}
+
+func TestNew_Arrays_RoundTrip(t *testing.T) {
+	tests := []string{
+		"a[0]=x&a[1]=y",
+		"a[3]=x&a[1]=y&b=z",
+		"items[0][id]=1&items[0][tags][0]=a&items[1][id]=2",
+		"a[50]=x",
+	}
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			q1, err := New(query)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			q2, err := New(q1.String())
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if !reflect.DeepEqual(q1.Values, q2.Values) {
+				t.Errorf("round trip through %q changed the tree", q1.String())
+			}
+		})
+	}
+}
+
+func Test_arrayIndex(t *testing.T) {
+	tests := []struct {
+		key    string
+		want   int
+		wantOk bool
+	}{
+		{key: "0", want: 0, wantOk: true},
+		{key: "20", want: 20, wantOk: true},
+		{key: "21", wantOk: false},
+		{key: "", wantOk: false},
+		{key: "01", wantOk: false},
+		{key: "-1", wantOk: false},
+		{key: "1a", wantOk: false},
+		{key: "99999999999999999999999", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := arrayIndex(tt.key, 20)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("arrayIndex() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestQS_String_ArrayFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		format  ArrayStyle
+		want    string
+		encoded string
+	}{
+		{
+			name:    "Repeat",
+			query:   "a=x&d=1&d=2&e[f]=3&e[f]=4",
+			format:  ArrayRepeat,
+			want:    "a=x&d=1&d=2&e[f]=3&e[f]=4",
+			encoded: "a=x&d=1&d=2&e[f]=3&e[f]=4",
+		},
+		{
+			name:    "Brackets",
+			query:   "a=x&d=1&d=2&e[f]=3&e[f]=4",
+			format:  ArrayBrackets,
+			want:    "a=x&d[]=1&d[]=2&e[f][]=3&e[f][]=4",
+			encoded: "a=x&d[]=1&d[]=2&e[f][]=3&e[f][]=4",
+		},
+		{
+			name:    "Indices",
+			query:   "a=x&d=1&d=2&e[f]=3&e[f]=4",
+			format:  ArrayIndices,
+			want:    "a=x&d[0]=1&d[1]=2&e[f][0]=3&e[f][1]=4",
+			encoded: "a=x&d[0]=1&d[1]=2&e[f][0]=3&e[f][1]=4",
+		},
+		{
+			name:    "Comma",
+			query:   "a=x&d=1&d=2&e[f]=3&e[f]=4",
+			format:  ArrayComma,
+			want:    "a=x&d=1,2&e[f]=3,4",
+			encoded: "a=x&d=1,2&e[f]=3,4",
+		},
+		{
+			name:    "Indices with indexed subkeys",
+			query:   "a[0]=x&a[1][b]=y&a[2]=z",
+			format:  ArrayIndices,
+			want:    "a=x&a=z&a[0][b]=y",
+			encoded: "a=x&a=z&a[0][b]=y",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, ArrayFormat(tt.format), Comma())
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.String(); got != tt.want {
+				t.Errorf("QS.String() = %v, want %v", got, tt.want)
+			}
+
+			encoded := q.EncodedString()
+			if encoded != tt.encoded {
+				t.Errorf("QS.EncodedString() = %v, want %v", encoded, tt.encoded)
+			}
+
+			q2, err := New(encoded, Comma())
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+			if !reflect.DeepEqual(q.Values, q2.Values) {
+				t.Errorf("round trip through %q changed the tree", encoded)
+			}
+		})
+	}
+}
+
+func TestNew_Comma(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  []Option
+		want  []interface{}
+	}{
+		{
+			name:  "Disabled",
+			query: "a=1,2",
+			want:  []interface{}{"1,2"},
+		},
+		{
+			name:  "Enabled",
+			query: "a=1,2&a=3",
+			opts:  []Option{Comma()},
+			want:  []interface{}{"1", "2", "3"},
+		},
+		{
+			name:  "Encoded comma",
+			query: "a=1%2C2,3",
+			opts:  []Option{Comma()},
+			want:  []interface{}{"1,2", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.GetAll("a"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
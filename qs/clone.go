@@ -0,0 +1,115 @@
+package qs
+
+import "sync"
+
+// Clone returns a deep copy of q. The copy has the same options as q, its own
+// copy of the tree and its own lock, so changes to one do not affect the
+// other. Values are copied as is, so values of reference types such as slices
+// or pointers are shared between the two.
+func (q *QS) Clone() *QS {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	c := *q
+	c.Values = q.root().clone()
+	c.warnings = append([]Warning(nil), q.warnings...)
+	c.mutex = &sync.RWMutex{}
+	c.origin = nil
+	c.subPath = nil
+
+	return &c
+}
+
+// EqualOption is a functional option used to configure Equal.
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	normalize bool
+}
+
+// NormalizeValues causes Equal to compare the string form of values rather
+// than the values themselves, so that a value of 1 set with Set is equal to
+// the parsed value "1".
+func NormalizeValues() EqualOption {
+	return func(c *equalConfig) {
+		c.normalize = true
+	}
+}
+
+// Equal reports whether q and other hold the same values at the same paths.
+// The order of keys is ignored, while the order of values at a single key is
+// not. Nodes without values are ignored. By default, values are compared with
+// reflect.DeepEqual. Use NormalizeValues to compare their string forms
+// instead, formatted as by the String method of q.
+func (q *QS) Equal(other *QS, opts ...EqualOption) bool {
+	cfg := &equalConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	equal := valuesEqual
+	if cfg.normalize {
+		q.mutex.RLock()
+		conv := q.converter()
+		q.mutex.RUnlock()
+
+		equal = func(a, b []interface{}) bool {
+			return conv.normalizedValuesEqual(a, b)
+		}
+	}
+
+	return len(diff(q, other, equal)) == 0
+}
+
+// normalizedValuesEqual reports whether a and b hold values with the same
+// string forms.
+func (c converter) normalizedValuesEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if c.format(a[i]) != c.format(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clone returns a deep copy of the node and its subkeys. The values
+// themselves are copied as is.
+func (n *node) clone() *node {
+	c := &node{
+		Key:      n.Key,
+		Values:   append(make([]interface{}, 0, len(n.Values)), n.Values...),
+		Children: make(map[string]*node, len(n.Children)),
+		Order:    make([]string, 0, len(n.Children)),
+	}
+
+	for _, k := range n.keys() {
+		c.Children[k] = n.Children[k].clone()
+		c.Order = append(c.Order, k)
+	}
+
+	return c
+}
+
+// emptyCopy returns a new QS with the same options as q, but with an empty
+// tree and its own lock.
+func (q *QS) emptyCopy() *QS {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	c := *q
+	c.Values = newNode("")
+	c.RawQuery = ""
+	c.warnings = nil
+	c.nodes = 0
+	c.values = nil
+	c.mutex = &sync.RWMutex{}
+	c.origin = nil
+	c.subPath = nil
+
+	return &c
+}
@@ -0,0 +1,122 @@
+package qs
+
+import (
+	"testing"
+)
+
+func TestQS_Clone(t *testing.T) {
+	q, err := New("a[b]=1&c=2", PathDelimiter("."), SortKeys())
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	q.Set([]interface{}{3}, "d")
+
+	c := q.Clone()
+	if c.mutex == q.mutex {
+		t.Errorf("QS.Clone() shares the lock with the original")
+	}
+	if c.PathDelimiter != "." || c.Sort == nil {
+		t.Errorf("QS.Clone() did not copy the options")
+	}
+	if got := c.Get("d"); got != 3 {
+		t.Errorf("QS.Clone() lost the type of a value: %#v", got)
+	}
+
+	c.Set([]interface{}{"x"}, "a.b")
+	c.Delete("c")
+	q.Add("y", "a.e")
+
+	if got, want := q.String(), "a[b]=1&a[e]=y&c=2&d=3"; got != want {
+		t.Errorf("original QS.String() = %v, want %v", got, want)
+	}
+	if got, want := c.String(), "a[b]=x&d=3"; got != want {
+		t.Errorf("cloned QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		set  []interface{}
+		opts []EqualOption
+		want bool
+	}{
+		{
+			name: "Same",
+			a:    "a[b]=1&c=2",
+			b:    "a[b]=1&c=2",
+			want: true,
+		},
+		{
+			name: "Key order ignored",
+			a:    "a[b]=1&a[d]=3&c=2",
+			b:    "c=2&a[d]=3&a[b]=1",
+			want: true,
+		},
+		{
+			name: "Value order matters",
+			a:    "a=1&a=2",
+			b:    "a=2&a=1",
+			want: false,
+		},
+		{
+			name: "Different values",
+			a:    "a=1",
+			b:    "a=2",
+			want: false,
+		},
+		{
+			name: "Missing key",
+			a:    "a=1&b=2",
+			b:    "a=1",
+			want: false,
+		},
+		{
+			name: "Different types",
+			a:    "a=1",
+			b:    "",
+			set:  []interface{}{1},
+			want: false,
+		},
+		{
+			name: "Different types normalized",
+			a:    "a=1",
+			b:    "",
+			set:  []interface{}{1},
+			opts: []EqualOption{NormalizeValues()},
+			want: true,
+		},
+		{
+			name: "Registered type normalized",
+			a:    "a=ord_7",
+			b:    "",
+			set:  []interface{}{orderID(7)},
+			opts: []EqualOption{NormalizeValues()},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(tt.a)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+			b, err := New(tt.b)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+			if tt.set != nil {
+				b.Set(tt.set, "a")
+			}
+
+			if got := a.Equal(b, tt.opts...); got != tt.want {
+				t.Errorf("QS.Equal() = %v, want %v", got, tt.want)
+			}
+			if got := b.Equal(a, tt.opts...); got != tt.want {
+				t.Errorf("QS.Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
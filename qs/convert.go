@@ -0,0 +1,201 @@
+package qs
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+)
+
+// TimeUnix can be passed to TimeLayouts to parse integer values as the number
+// of seconds since the Unix epoch.
+const TimeUnix = "unix"
+
+// DefaultTimeLayouts are the layouts used to parse time.Time values when the
+// TimeLayouts option is not set. Layouts are tried in order.
+var DefaultTimeLayouts = []string{time.RFC3339, "2006-01-02", TimeUnix}
+
+// ConversionError will be returned when a value stored in a QS cannot be
+// converted to the requested type.
+type ConversionError struct {
+	// Path is the path of the node holding the value.
+	Path []string
+	// Value is the raw value that failed to convert.
+	Value interface{}
+	// Type is the type the value was being converted to.
+	Type reflect.Type
+	// Err is the underlying conversion error, if any.
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("qs: cannot convert %q at %s to %s", fmt.Sprintf("%v", e.Value), formatPath(e.Path), e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// formatPath renders a path in the bracketed form used in query strings
+// e.g. []string{"a", "b", "c"} => a[b][c].
+func formatPath(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(path[0])
+	for _, p := range path[1:] {
+		b.WriteString("[" + p + "]")
+	}
+	return b.String()
+}
+
+// assign converts raw into the type of dst and stores it. The conversion
+// rules are the same as the ones used by the typed getters and depend on the
+// conversion mode.
+func (c converter) assign(dst reflect.Value, raw interface{}) error {
+	if raw != nil {
+		rv := reflect.ValueOf(raw)
+		if rv.Type().AssignableTo(dst.Type()) {
+			dst.Set(rv)
+			return nil
+		}
+	}
+
+	if conv, ok := c.lookup(dst.Type()); ok && conv.Decode != nil {
+		v, err := conv.Decode(c.toString(raw))
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(v)
+		if v == nil || !rv.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("decode func returned %T, not %s", v, dst.Type())
+		}
+		dst.Set(rv)
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		p := reflect.New(dst.Type().Elem())
+		if err := c.assign(p.Elem(), raw); err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+	}
+
+	if dst.Type() == timeType {
+		t, err := parseTime(c.toString(raw), c.timeLayouts())
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(c.toString(raw)))
+	}
+
+	if dst.Type() == urlType {
+		u, err := url.Parse(c.toString(raw))
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	if dst.Type() == durationType {
+		d, err := c.toDuration(raw)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(c.toString(raw))
+	case reflect.Bool:
+		b, err := c.toBool(raw)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := c.toInt64(raw)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("%d overflows %s", i, dst.Type())
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := c.toUint64(raw)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowUint(u) {
+			return fmt.Errorf("%d overflows %s", u, dst.Type())
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := c.toFloat64(raw)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowFloat(f) {
+			return fmt.Errorf("%g overflows %s", f, dst.Type())
+		}
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", dst.Type())
+	}
+
+	return nil
+}
+
+// timeLayouts returns the layouts used to parse time.Time values.
+func (c converter) timeLayouts() []string {
+	if c.layouts == nil {
+		return DefaultTimeLayouts
+	}
+	return c.layouts
+}
+
+// parseTime parses s using the first matching layout. The TimeUnix layout
+// matches integers, which are returned in UTC.
+func parseTime(s string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if layout == TimeUnix {
+			if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return time.Unix(sec, 0).UTC(), nil
+			}
+			continue
+		}
+
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("does not match any of the layouts %q", layouts)
+}
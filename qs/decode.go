@@ -0,0 +1,258 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidTarget will be returned when the value passed to Decode or
+// Unmarshal is not a non-nil pointer.
+var ErrInvalidTarget = errors.New("decode target must be a non-nil pointer")
+
+// Unmarshal parses the raw query string and stores the result in the value
+// pointed to by v. Any provided options are passed through to New. See
+// Decode for a description of how the parsed tree is mapped onto v.
+func Unmarshal(rawQuery string, v interface{}, opts ...Option) error {
+	q, err := New(rawQuery, opts...)
+	if err != nil {
+		return err
+	}
+
+	return q.Decode(v)
+}
+
+// Decode walks the parsed tree and stores the result in the value pointed to
+// by v. Struct fields are matched to keys using the qs struct tag, falling
+// back to the field name if no tag is present. A tag of "-" skips the field.
+// Fields of untagged embedded structs are treated as if they belong to the
+// parent struct.
+//
+// Nested structs and maps are filled from the subkeys of a node, slices of
+// scalars are filled from all values at a node, and slices of structs or maps
+// are filled from the subkeys of a node in order. Pointers are allocated as
+// needed, and empty interfaces receive either the value, a slice of values, or
+// a map[string]interface{} of the subkeys, with any values of the node under
+// the empty key unless a subkey named "" takes its place. Values are converted
+// using the same rules as the typed getters, including any registered
+// converters. A *ConversionError is returned if a value cannot be converted.
+func (q *QS) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidTarget
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.converter().decodeNode(q.root(), rv.Elem(), nil)
+}
+
+func (c converter) decodeNode(n *node, v reflect.Value, path []string) error {
+	if _, ok := c.lookup(v.Type()); !ok && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return c.decodeNode(n, v.Elem(), path)
+	}
+
+	if c.isScalarType(v.Type()) {
+		if len(n.Values) == 0 {
+			return nil
+		}
+		return c.decodeValue(n.Values[0], v, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return c.decodeStruct(n, v, path)
+	case reflect.Map:
+		return c.decodeMap(n, v, path)
+	case reflect.Slice:
+		return c.decodeSlice(n, v, path)
+	case reflect.Array:
+		return c.decodeArray(n, v, path)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return &ConversionError{Path: path, Type: v.Type(), Err: errors.New("non-empty interface")}
+		}
+		if iface := nodeInterface(n); iface != nil {
+			v.Set(reflect.ValueOf(iface))
+		}
+		return nil
+	}
+
+	return &ConversionError{Path: path, Type: v.Type(), Err: fmt.Errorf("unsupported type %s", v.Type())}
+}
+
+func (c converter) decodeValue(raw interface{}, v reflect.Value, path []string) error {
+	if err := c.assign(v, raw); err != nil {
+		return &ConversionError{Path: path, Value: raw, Type: v.Type(), Err: err}
+	}
+	return nil
+}
+
+func (c converter) decodeStruct(n *node, v reflect.Value, path []string) error {
+	for _, f := range cachedFields(v.Type()) {
+		child, ok := n.Children[f.name]
+		if !ok {
+			continue
+		}
+
+		if err := c.decodeNode(child, fieldByIndex(v, f.index), appendPath(path, f.name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c converter) decodeMap(n *node, v reflect.Value, path []string) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return &ConversionError{Path: path, Type: t, Err: errors.New("map keys must be strings")}
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+
+	for _, k := range n.keys() {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := c.decodeNode(n.Children[k], elem, appendPath(path, k)); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), elem)
+	}
+
+	return nil
+}
+
+func (c converter) decodeSlice(n *node, v reflect.Value, path []string) error {
+	t := v.Type()
+
+	if c.isScalarType(t.Elem()) {
+		s := reflect.MakeSlice(t, len(n.Values), len(n.Values))
+		for i, raw := range n.Values {
+			if err := c.decodeValue(raw, indirect(s.Index(i)), path); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	keys := n.keys()
+	s := reflect.MakeSlice(t, len(keys), len(keys))
+	for i, k := range keys {
+		if err := c.decodeNode(n.Children[k], s.Index(i), appendPath(path, k)); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+
+	return nil
+}
+
+func (c converter) decodeArray(n *node, v reflect.Value, path []string) error {
+	if c.isScalarType(v.Type().Elem()) {
+		for i, raw := range n.Values {
+			if i >= v.Len() {
+				break
+			}
+			if err := c.decodeValue(raw, indirect(v.Index(i)), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, k := range n.keys() {
+		if i >= v.Len() {
+			break
+		}
+		if err := c.decodeNode(n.Children[k], v.Index(i), appendPath(path, k)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isScalarType reports whether values of type t are decoded from a single
+// value rather than from the subkeys of a node.
+func (c converter) isScalarType(t reflect.Type) bool {
+	if _, ok := c.lookup(t); ok {
+		return true
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if _, ok := c.lookup(t); ok {
+		return true
+	}
+
+	if t == urlType || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return false
+	}
+
+	return true
+}
+
+// indirect allocates any nil pointers in v and returns the value that is
+// ultimately pointed to.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// nodeInterface converts a node into plain Go values. A node with only values
+// becomes either its single value or a slice of its values. A node with
+// subkeys becomes a map[string]interface{}. If a node has both values and
+// subkeys, the values are stored under the empty key, unless the node also
+// has a subkey named "" e.g. a=1&a[][b]=2. The subkey takes precedence in
+// that case and the values are left out.
+func nodeInterface(n *node) interface{} {
+	var vals interface{}
+	switch len(n.Values) {
+	case 0:
+	case 1:
+		vals = n.Values[0]
+	default:
+		vals = append([]interface{}(nil), n.Values...)
+	}
+
+	if len(n.Children) == 0 {
+		return vals
+	}
+
+	m := make(map[string]interface{}, len(n.Children)+1)
+	for k, child := range n.Children {
+		m[k] = nodeInterface(child)
+	}
+	if _, ok := m[""]; !ok && vals != nil {
+		m[""] = vals
+	}
+
+	return m
+}
+
+// appendPath returns a new path with key appended, leaving path untouched.
+func appendPath(path []string, key string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = key
+	return p
+}
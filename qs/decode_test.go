@@ -0,0 +1,147 @@
+package qs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type decodeFilter struct {
+	Status string `qs:"status"`
+	Owner  struct {
+		ID int `qs:"id"`
+	} `qs:"owner"`
+}
+
+type decodePage struct {
+	Number int `qs:"number"`
+	Size   int `qs:"size"`
+}
+
+type decodeTarget struct {
+	decodePage
+	Filter  decodeFilter      `qs:"filter"`
+	Tags    []string          `qs:"tags"`
+	IDs     []int             `qs:"ids"`
+	Labels  map[string]string `qs:"labels"`
+	Sort    *string           `qs:"sort"`
+	Missing *int              `qs:"missing"`
+	Debug   bool              `qs:"debug"`
+	Ignored string            `qs:"-"`
+	Extra   interface{}       `qs:"extra"`
+	Plain   float64
+}
+
+func TestUnmarshal(t *testing.T) {
+	query := "number=2&size=50&filter[status]=open&filter[owner][id]=7&tags[]=a&tags[]=b" +
+		"&ids=1&ids=2&labels[env]=prod&labels[team]=core&sort=name&debug=true&Ignored=x" +
+		"&extra[a]=1&extra[b]=2&extra[b]=3&Plain=1.5"
+
+	var got decodeTarget
+	if err := Unmarshal(query, &got); err != nil {
+		t.Fatalf("Unmarshal failed with err, %s", err)
+	}
+
+	sort := "name"
+	want := decodeTarget{
+		decodePage: decodePage{Number: 2, Size: 50},
+		Filter:     decodeFilter{Status: "open"},
+		Tags:       []string{"a", "b"},
+		IDs:        []int{1, 2},
+		Labels:     map[string]string{"env": "prod", "team": "core"},
+		Sort:       &sort,
+		Debug:      true,
+		Extra:      map[string]interface{}{"a": "1", "b": []interface{}{"2", "3"}},
+		Plain:      1.5,
+	}
+	want.Filter.Owner.ID = 7
+
+	if !cmp.Equal(got, want, cmp.AllowUnexported(decodeTarget{})) {
+		t.Errorf("Unmarshal() mismatch: %s", cmp.Diff(got, want, cmp.AllowUnexported(decodeTarget{})))
+	}
+}
+
+func TestUnmarshal_SliceOfStructs(t *testing.T) {
+	type item struct {
+		ID   int    `qs:"id"`
+		Name string `qs:"name"`
+	}
+	type target struct {
+		Items []item `qs:"items"`
+	}
+
+	var got target
+	if err := Unmarshal("items[a][id]=1&items[a][name]=x&items[b][id]=2", &got); err != nil {
+		t.Fatalf("Unmarshal failed with err, %s", err)
+	}
+
+	want := target{Items: []item{{ID: 1, Name: "x"}, {ID: 2}}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Unmarshal() mismatch: %s", cmp.Diff(got, want))
+	}
+}
+
+func TestQS_Decode_Errors(t *testing.T) {
+	type target struct {
+		Page  int  `qs:"page"`
+		Small int8 `qs:"small"`
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		target   interface{}
+		wantPath []string
+		wantErr  error
+	}{
+		{
+			name:    "Non-pointer target",
+			query:   "page=1",
+			target:  target{},
+			wantErr: ErrInvalidTarget,
+		},
+		{
+			name:    "Nil target",
+			query:   "page=1",
+			target:  (*target)(nil),
+			wantErr: ErrInvalidTarget,
+		},
+		{
+			name:     "Invalid int",
+			query:    "page=abc",
+			target:   &target{},
+			wantPath: []string{"page"},
+		},
+		{
+			name:     "Overflow",
+			query:    "small=300",
+			target:   &target{},
+			wantPath: []string{"small"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			err = q.Decode(tt.target)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("QS.Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("QS.Decode() error = %v, want *ConversionError", err)
+			}
+			if !cmp.Equal(convErr.Path, tt.wantPath) {
+				t.Errorf("ConversionError.Path = %v, want %v", convErr.Path, tt.wantPath)
+			}
+		})
+	}
+}
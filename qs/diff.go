@@ -0,0 +1,135 @@
+package qs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ChangeKind describes how a path differs between two query strings.
+type ChangeKind int
+
+const (
+	// Added means the path only holds values in the second query string.
+	Added ChangeKind = iota + 1
+	// Removed means the path only holds values in the first query string.
+	Removed
+	// Modified means the path holds different values in each query string.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a single difference between two query strings.
+type Change struct {
+	// Path is the path of the node that changed.
+	Path []string
+	// Kind describes the change.
+	Kind ChangeKind
+	// Old holds the values in the first query string. It is nil for Added.
+	Old []interface{}
+	// New holds the values in the second query string. It is nil for
+	// Removed.
+	New []interface{}
+}
+
+// Diff compares the values held by a and b and returns the changes needed to
+// turn a into b. Only nodes that hold values are compared, so differences in
+// the order of keys are ignored while differences in the order of values at
+// a single key are not. Values are compared with reflect.DeepEqual. Removed
+// and modified paths are returned in the order of a, followed by added paths
+// in the order of b. A nil QS is treated as empty.
+func Diff(a, b *QS) []Change {
+	return diff(a, b, valuesEqual)
+}
+
+func diff(a, b *QS, equal func(x, y []interface{}) bool) []Change {
+	var aLeaves, bLeaves []leaf
+	if a != nil {
+		aLeaves = a.leaves()
+	}
+	if b != nil {
+		bLeaves = b.leaves()
+	}
+
+	bIndex := make(map[string]leaf, len(bLeaves))
+	for _, l := range bLeaves {
+		bIndex[pathKey(l.path)] = l
+	}
+
+	changes := make([]Change, 0)
+	seen := make(map[string]bool, len(aLeaves))
+	for _, l := range aLeaves {
+		key := pathKey(l.path)
+		seen[key] = true
+
+		other, ok := bIndex[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: l.path, Kind: Removed, Old: l.values})
+		case !equal(l.values, other.values):
+			changes = append(changes, Change{Path: l.path, Kind: Modified, Old: l.values, New: other.values})
+		}
+	}
+
+	for _, l := range bLeaves {
+		if !seen[pathKey(l.path)] {
+			changes = append(changes, Change{Path: l.path, Kind: Added, New: l.values})
+		}
+	}
+
+	return changes
+}
+
+// Patch applies the provided changes, such as those returned by Diff. Added
+// and modified paths are set to the new values. Removed paths lose their
+// values, and are deleted along with any empty ancestors if they have no
+// subkeys. Paths are used as is and are not split on the PathDelimiter, and
+// empty subkeys such as the one in a[] are kept.
+func (q *QS) Patch(changes []Change) {
+	q.lock()
+	defer q.unlock()
+
+	for _, c := range changes {
+		if len(c.Path) == 0 {
+			continue
+		}
+
+		switch c.Kind {
+		case Added, Modified:
+			n := q.root()
+			for _, p := range c.Path {
+				n = n.child(p)
+			}
+			n.Values = append([]interface{}{}, c.New...)
+		case Removed:
+			n := q.find(c.Path...)
+			if n == nil {
+				continue
+			}
+			n.Values = make([]interface{}, 0)
+			if len(n.Children) == 0 {
+				q.delete(c.Path)
+			}
+		}
+	}
+}
+
+func valuesEqual(a, b []interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// pathKey joins a path into a single string that can be used as a map key.
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
@@ -0,0 +1,124 @@
+package qs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Change
+	}{
+		{
+			name: "Equal",
+			a:    "a=1&b[c]=2",
+			b:    "b[c]=2&a=1",
+			want: []Change{},
+		},
+		{
+			name: "Added",
+			a:    "a=1",
+			b:    "a=1&b[c]=2",
+			want: []Change{
+				{Path: []string{"b", "c"}, Kind: Added, New: []interface{}{"2"}},
+			},
+		},
+		{
+			name: "Removed",
+			a:    "a=1&b[c]=2",
+			b:    "a=1",
+			want: []Change{
+				{Path: []string{"b", "c"}, Kind: Removed, Old: []interface{}{"2"}},
+			},
+		},
+		{
+			name: "Modified",
+			a:    "a=1&a=2",
+			b:    "a=2&a=1",
+			want: []Change{
+				{Path: []string{"a"}, Kind: Modified, Old: []interface{}{"1", "2"}, New: []interface{}{"2", "1"}},
+			},
+		},
+		{
+			name: "Mixed",
+			a:    "a=1&b=2&c=3",
+			b:    "d=4&c=3&a=0",
+			want: []Change{
+				{Path: []string{"a"}, Kind: Modified, Old: []interface{}{"1"}, New: []interface{}{"0"}},
+				{Path: []string{"b"}, Kind: Removed, Old: []interface{}{"2"}},
+				{Path: []string{"d"}, Kind: Added, New: []interface{}{"4"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(tt.a)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+			b, err := New(tt.b)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			got := Diff(a, b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+
+			a.Patch(got)
+			if changes := Diff(a, b); len(changes) != 0 {
+				t.Errorf("Diff() after Patch() = %v, want no changes", changes)
+			}
+		})
+	}
+}
+
+func TestDiff_Nil(t *testing.T) {
+	q, _ := New("a=1")
+
+	want := []Change{{Path: []string{"a"}, Kind: Added, New: []interface{}{"1"}}}
+	if got := Diff(nil, q); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Patch(t *testing.T) {
+	q, err := New("a[b]=1&a[b][c]=2&d[e]=3&f=4")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	q.Patch([]Change{
+		{Path: []string{"a", "b"}, Kind: Removed},
+		{Path: []string{"d", "e"}, Kind: Removed},
+		{Path: []string{"f"}, Kind: Modified, New: []interface{}{5}},
+		{Path: []string{"g", "h"}, Kind: Added, New: []interface{}{"6"}},
+		{Path: []string{"z"}, Kind: Removed},
+	})
+
+	if got, want := q.String(), "a[b][c]=2&f=5&g[h]=6"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Patch_EmptyKey(t *testing.T) {
+	b, err := FromJSON([]byte(`{"":"x","a":"1"}`), JSONValuesKey("_"))
+	if err != nil {
+		t.Fatalf("FromJSON failed with err, %s", err)
+	}
+
+	q, err := New("")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	q.Patch(Diff(q, b))
+
+	if !q.Equal(b) {
+		t.Errorf("QS.Patch() left changes %v", Diff(q, b))
+	}
+}
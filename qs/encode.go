@@ -0,0 +1,217 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrUnsupportedType will be returned when Marshal is given a value that
+// cannot be represented as a query string.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// Marshal converts v into a new QS. Any provided options are applied to the
+// returned QS. The top level value must be a struct, a map with string keys,
+// a slice or array of structs or maps, or a pointer to one of these. A top
+// level slice of scalars is rejected with ErrUnsupportedType, since its values
+// would have no key.
+//
+// Struct fields are mapped to keys using the qs struct tag, falling back to
+// the field name if no tag is present. A tag of "-" skips the field and the
+// "omitempty" option skips the field if it holds its zero value. Fields of
+// untagged embedded structs are treated as if they belong to the parent
+// struct. Nested structs and maps become subkeys, slices of scalars become
+// multiple values at the same key, and slices of structs or maps become
+// indexed subkeys. Nil pointers and interfaces are skipped. Scalar values are
+// stored as is, just as they would be by Set or Add.
+func Marshal(v interface{}, opts ...Option) (*QS, error) {
+	q, err := New("", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := q.converter()
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: nil %s", ErrUnsupportedType, rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || c.isScalarType(rv.Type()) {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+
+	if err := c.encodeNode(q.Values, rv); err != nil {
+		return nil, err
+	}
+
+	if len(q.Values.Values) > 0 {
+		return nil, fmt.Errorf("%w: top level %s of scalars", ErrUnsupportedType, rv.Type())
+	}
+
+	return q, nil
+}
+
+// MarshalString converts v into an encoded query string. It is shorthand for
+// calling Marshal followed by EncodedString.
+func MarshalString(v interface{}, opts ...Option) (string, error) {
+	q, err := Marshal(v, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return q.EncodedString(), nil
+}
+
+func (c converter) encodeNode(n *node, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if c.isScalarType(v.Type()) {
+		n.Values = append(n.Values, v.Interface())
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return c.encodeStruct(n, v)
+	case reflect.Map:
+		return c.encodeMap(n, v)
+	case reflect.Slice, reflect.Array:
+		return c.encodeSlice(n, v)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+}
+
+func (c converter) encodeStruct(n *node, v reflect.Value) error {
+	for _, f := range cachedFields(v.Type()) {
+		fv, ok := fieldByIndexNoAlloc(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		if err := c.encodeChild(n, f.name, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c converter) encodeMap(n *node, v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: map keys must be strings, got %s", ErrUnsupportedType, v.Type())
+	}
+
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		mv := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))
+		if err := c.encodeChild(n, k, mv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c converter) encodeSlice(n *node, v reflect.Value) error {
+	scalars := true
+	for i := 0; i < v.Len(); i++ {
+		if !c.isScalarValue(v.Index(i)) {
+			scalars = false
+			break
+		}
+	}
+
+	if scalars {
+		for i := 0; i < v.Len(); i++ {
+			if err := c.encodeNode(n, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := c.encodeChild(n, fmt.Sprintf("%d", i), v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeChild encodes v into the child of n with the given key. The child is
+// discarded if nothing was written to it.
+func (c converter) encodeChild(n *node, key string, v reflect.Value) error {
+	_, existed := n.Children[key]
+	child := n.child(key)
+	if err := c.encodeNode(child, v); err != nil {
+		return err
+	}
+
+	if !existed && len(child.Values) == 0 && len(child.Children) == 0 {
+		n.removeChild(key)
+	}
+
+	return nil
+}
+
+// isScalarValue reports whether the dynamic value held by v is stored as a
+// value rather than as subkeys. Nil pointers and interfaces count as scalars
+// since they produce nothing.
+func (c converter) isScalarValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return c.isScalarType(v.Type())
+}
+
+// fieldByIndexNoAlloc is like fieldByIndex, but reports false instead of
+// allocating when it encounters a nil embedded struct pointer.
+func fieldByIndexNoAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
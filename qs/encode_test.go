@@ -0,0 +1,182 @@
+package qs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshal(t *testing.T) {
+	type owner struct {
+		ID int `qs:"id"`
+	}
+	type filter struct {
+		Status string `qs:"status"`
+		Owner  *owner `qs:"owner"`
+	}
+	type page struct {
+		Number int `qs:"number,omitempty"`
+	}
+	type item struct {
+		ID int `qs:"id"`
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "Flat struct",
+			v: struct {
+				A string
+				B int  `qs:"b"`
+				C bool `qs:"-"`
+			}{A: "x", B: 2, C: true},
+			want: "A=x&b=2",
+		},
+		{
+			name: "Nested struct",
+			v: struct {
+				Filter filter `qs:"filter"`
+			}{Filter: filter{Status: "open", Owner: &owner{ID: 7}}},
+			want: "filter[status]=open&filter[owner][id]=7",
+		},
+		{
+			name: "Nil pointer skipped",
+			v: &struct {
+				Filter filter `qs:"filter"`
+			}{Filter: filter{Status: "open"}},
+			want: "filter[status]=open",
+		},
+		{
+			name: "Omitempty",
+			v: struct {
+				page
+				Size int `qs:"size,omitempty"`
+				Sort string
+			}{},
+			want: "Sort=",
+		},
+		{
+			name: "Embedded struct",
+			v: struct {
+				page
+				Size int `qs:"size"`
+			}{page: page{Number: 3}, Size: 10},
+			want: "number=3&size=10",
+		},
+		{
+			name: "Slice of scalars",
+			v: struct {
+				Tags []string `qs:"tags"`
+			}{Tags: []string{"a", "b"}},
+			want: "tags=a&tags=b",
+		},
+		{
+			name: "Slice of structs",
+			v: struct {
+				Items []item `qs:"items"`
+			}{Items: []item{{ID: 1}, {ID: 2}}},
+			want: "items[0][id]=1&items[1][id]=2",
+		},
+		{
+			name: "Map of interfaces",
+			v: map[string]interface{}{
+				"a": 1,
+				"b": []interface{}{"x", true},
+				"c": map[string]interface{}{"d": 1.5},
+			},
+			want: "a=1&b=x&b=true&c[d]=1.5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal failed with err, %s", err)
+			}
+
+			if got := q.String(); !assertQueryStringsEqual(got, tt.want) {
+				t.Errorf("Marshal().String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal_KeepsValueTypes(t *testing.T) {
+	q, err := Marshal(map[string]interface{}{"a": 1, "b": true})
+	if err != nil {
+		t.Fatalf("Marshal failed with err, %s", err)
+	}
+
+	if got := q.Get("a"); got != 1 {
+		t.Errorf("QS.Get() = %#v, want %#v", got, 1)
+	}
+	if got := q.Get("b"); got != true {
+		t.Errorf("QS.Get() = %#v, want %#v", got, true)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	type target struct {
+		Page   int               `qs:"page"`
+		Tags   []string          `qs:"tags"`
+		Labels map[string]string `qs:"labels"`
+	}
+
+	in := target{Page: 2, Tags: []string{"a b", "c&d"}, Labels: map[string]string{"env": "prod"}}
+	s, err := MarshalString(in)
+	if err != nil {
+		t.Fatalf("MarshalString failed with err, %s", err)
+	}
+
+	var out target
+	if err := Unmarshal(s, &out); err != nil {
+		t.Fatalf("Unmarshal failed with err, %s", err)
+	}
+
+	if !cmp.Equal(in, out) {
+		t.Errorf("round trip mismatch: %s", cmp.Diff(in, out))
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "Nil", v: nil},
+		{name: "Nil pointer", v: (*struct{})(nil)},
+		{name: "Scalar", v: 1},
+		{name: "Non-string map keys", v: map[int]string{1: "a"}},
+		{name: "Slice of scalars", v: []int{1, 2}},
+		{name: "Array of scalars", v: [2]string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.v); !errors.Is(err, ErrUnsupportedType) {
+				t.Errorf("Marshal() error = %v, want %v", err, ErrUnsupportedType)
+			}
+		})
+	}
+}
+
+func TestMarshal_TopLevelSlice(t *testing.T) {
+	type item struct {
+		ID int `qs:"id"`
+	}
+
+	s, err := MarshalString([]interface{}{item{ID: 1}, map[string]string{"id": "2"}})
+	if err != nil {
+		t.Fatalf("MarshalString failed with err, %s", err)
+	}
+	if want := "0[id]=1&1[id]=2"; s != want {
+		t.Errorf("MarshalString() = %v, want %v", s, want)
+	}
+
+	if s, err := MarshalString([]int{}); err != nil || s != "" {
+		t.Errorf("MarshalString() = %q, %v, want an empty string", s, err)
+	}
+}
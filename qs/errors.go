@@ -0,0 +1,84 @@
+package qs
+
+import "fmt"
+
+// Reason describes why a query string could not be parsed.
+type Reason int
+
+const (
+	// ReasonBadEscape means a key or value contains a '%' that is not
+	// followed by two hexadecimal digits.
+	ReasonBadEscape Reason = iota + 1
+	// ReasonUnbalanced means the brackets in a key are unbalanced.
+	ReasonUnbalanced
+	// ReasonDepthExceeded means a key has more subkeys than MaxDepth allows.
+	ReasonDepthExceeded
+	// ReasonLimitExceeded means the query string exceeds a configured limit.
+	ReasonLimitExceeded
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonBadEscape:
+		return "invalid escape sequence"
+	case ReasonUnbalanced:
+		return "unbalanced brackets"
+	case ReasonDepthExceeded:
+		return "max depth exceeded"
+	case ReasonLimitExceeded:
+		return "limit exceeded"
+	}
+	return fmt.Sprintf("Reason(%d)", int(r))
+}
+
+// ParseError will be returned when a query string cannot be parsed. Every
+// ParseError matches ErrInvalidQS when using errors.Is. It also matches the
+// sentinel error for its reason: ErrUnbalanced, ErrDepthExceeded or
+// ErrLimitExceeded.
+type ParseError struct {
+	// Key is the raw, still escaped, key of the offending pair.
+	Key string
+	// Offset is the byte offset in RawQuery where the problem was found.
+	Offset int
+	// Reason describes the problem.
+	Reason Reason
+	// Limit is the name of the exceeded limit e.g. "MaxKeyLength". It is only
+	// set when Reason is ReasonLimitExceeded.
+	Limit string
+}
+
+func (e *ParseError) Error() string {
+	reason := e.Reason.String()
+	if e.Limit != "" {
+		reason += " (" + e.Limit + ")"
+	}
+	return fmt.Sprintf("qs: %s in key %q at offset %d", reason, e.Key, e.Offset)
+}
+
+// Is reports whether target is ErrInvalidQS or the sentinel error for the
+// reason of the error.
+func (e *ParseError) Is(target error) bool {
+	return target == ErrInvalidQS || (target != nil && target == e.Unwrap())
+}
+
+// Unwrap returns the sentinel error for the reason of the error, if any.
+func (e *ParseError) Unwrap() error {
+	switch e.Reason {
+	case ReasonUnbalanced:
+		return ErrUnbalanced
+	case ReasonDepthExceeded:
+		return ErrDepthExceeded
+	case ReasonLimitExceeded:
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+// Warning describes a problem that was worked around while parsing in
+// lenient mode.
+type Warning struct {
+	*ParseError
+	// Dropped reports whether the offending pair was discarded entirely. If
+	// false, the pair was kept in an altered form.
+	Dropped bool
+}
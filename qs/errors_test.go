@@ -0,0 +1,286 @@
+package qs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNew_ParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []Option
+		want     ParseError
+		sentinel error
+	}{
+		{
+			name:     "Bad escape in key",
+			query:    "a=1&b%zz=2",
+			want:     ParseError{Key: "b%zz", Offset: 5, Reason: ReasonBadEscape},
+			sentinel: ErrInvalidQS,
+		},
+		{
+			name:     "Bad escape in value",
+			query:    "a=1&b=x%2",
+			want:     ParseError{Key: "b", Offset: 7, Reason: ReasonBadEscape},
+			sentinel: ErrInvalidQS,
+		},
+		{
+			name:     "Double open bracket",
+			query:    "a=1&b[[c]=2",
+			want:     ParseError{Key: "b[[c]", Offset: 6, Reason: ReasonUnbalanced},
+			sentinel: ErrUnbalanced,
+		},
+		{
+			name:     "Stray close bracket",
+			query:    "b[c]]=2",
+			want:     ParseError{Key: "b[c]]", Offset: 4, Reason: ReasonUnbalanced},
+			sentinel: ErrUnbalanced,
+		},
+		{
+			name:     "Unclosed bracket",
+			query:    "a=1&b[c=2",
+			want:     ParseError{Key: "b[c", Offset: 5, Reason: ReasonUnbalanced},
+			sentinel: ErrUnbalanced,
+		},
+		{
+			name:     "Strict depth",
+			query:    "a[b][c]=1",
+			opts:     []Option{MaxDepth(2), StrictDepth()},
+			want:     ParseError{Key: "a[b][c]", Offset: 4, Reason: ReasonDepthExceeded},
+			sentinel: ErrDepthExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.query, tt.opts...)
+
+			var pErr *ParseError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("New() error = %v, want *ParseError", err)
+			}
+			if *pErr != tt.want {
+				t.Errorf("New() error = %+v, want %+v", *pErr, tt.want)
+			}
+			if !errors.Is(err, ErrInvalidQS) {
+				t.Errorf("errors.Is(%v, ErrInvalidQS) = false", err)
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestNew_StrictDepth_WithinLimit(t *testing.T) {
+	if _, err := New("a[b]=1", MaxDepth(2), StrictDepth()); err != nil {
+		t.Errorf("New() error = %v, want nil", err)
+	}
+}
+
+func TestParseError_Is(t *testing.T) {
+	err := &ParseError{Reason: ReasonBadEscape}
+	if errors.Is(err, ErrUnbalanced) {
+		t.Errorf("errors.Is(%v, ErrUnbalanced) = true", err)
+	}
+	if errors.Is(err, nil) {
+		t.Errorf("errors.Is(%v, nil) = true", err)
+	}
+}
+
+func TestNew_Lenient(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []Option
+		path     []string
+		want     []interface{}
+		warnings []Warning
+	}{
+		{
+			name:     "Well formed",
+			query:    "a[b]=1",
+			path:     []string{"a", "b"},
+			want:     []interface{}{"1"},
+			warnings: []Warning{},
+		},
+		{
+			name:  "Unbalanced key kept literally",
+			query: "a[[b]=1&c=2",
+			path:  []string{"a[[b]"},
+			want:  []interface{}{"1"},
+			warnings: []Warning{
+				{ParseError: &ParseError{Key: "a[[b]", Offset: 2, Reason: ReasonUnbalanced}},
+			},
+		},
+		{
+			name:  "Valid pairs survive",
+			query: "a[[b]=1&c=2",
+			path:  []string{"c"},
+			want:  []interface{}{"2"},
+			warnings: []Warning{
+				{ParseError: &ParseError{Key: "a[[b]", Offset: 2, Reason: ReasonUnbalanced}},
+			},
+		},
+		{
+			name:  "Bad escape in value kept literally",
+			query: "c=2&q=100%",
+			path:  []string{"q"},
+			want:  []interface{}{"100%"},
+			warnings: []Warning{
+				{ParseError: &ParseError{Key: "q", Offset: 9, Reason: ReasonBadEscape}},
+			},
+		},
+		{
+			name:  "Bad escape in key kept literally",
+			query: "a%zz[b]=1",
+			path:  []string{"a%zz", "b"},
+			want:  []interface{}{"1"},
+			warnings: []Warning{
+				{ParseError: &ParseError{Key: "a%zz[b]", Offset: 1, Reason: ReasonBadEscape}},
+			},
+		},
+		{
+			name:  "Strict depth truncates",
+			query: "a[b][c]=1",
+			opts:  []Option{MaxDepth(1), StrictDepth()},
+			path:  []string{"a", "[b][c]"},
+			want:  []interface{}{"1"},
+			warnings: []Warning{
+				{ParseError: &ParseError{Key: "a[b][c]", Offset: 1, Reason: ReasonDepthExceeded}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, append(tt.opts, Lenient())...)
+			if err != nil {
+				t.Fatalf("New() error = %v, want nil", err)
+			}
+
+			if got := q.GetAll(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAll() = %v, want %v", got, tt.want)
+			}
+			if got := q.Warnings(); !reflect.DeepEqual(got, tt.warnings) {
+				t.Errorf("QS.Warnings() = %v, want %v", got, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestNew_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		opts    []Option
+		wantErr ParseError
+		// want is the stringified tree in lenient mode.
+		want     string
+		warnings int
+	}{
+		{
+			name:     "Within limits",
+			query:    "a=1&b[c]=2",
+			opts:     []Option{ParameterLimit(2), MaxNodes(3), MaxKeyLength(4), MaxValueLength(1), MaxValuesPerKey(1)},
+			want:     "a=1&b[c]=2",
+			warnings: 0,
+		},
+		{
+			name:     "ParameterLimit",
+			query:    "a=1&b=2&c=3&d=4",
+			opts:     []Option{ParameterLimit(2)},
+			wantErr:  ParseError{Key: "c", Offset: 8, Reason: ReasonLimitExceeded, Limit: "ParameterLimit"},
+			want:     "a=1&b=2",
+			warnings: 1,
+		},
+		{
+			name:     "MaxNodes",
+			query:    "a[b]=1&a[c]=2&a[b]=3&d=4",
+			opts:     []Option{MaxNodes(3)},
+			wantErr:  ParseError{Key: "d", Offset: 21, Reason: ReasonLimitExceeded, Limit: "MaxNodes"},
+			want:     "a[b]=1&a[b]=3&a[c]=2",
+			warnings: 1,
+		},
+		{
+			name:     "MaxKeyLength",
+			query:    "abc=1&abcd=2&ab=3",
+			opts:     []Option{MaxKeyLength(3)},
+			wantErr:  ParseError{Key: "abcd", Offset: 6, Reason: ReasonLimitExceeded, Limit: "MaxKeyLength"},
+			want:     "abc=1&ab=3",
+			warnings: 1,
+		},
+		{
+			name:     "MaxValueLength",
+			query:    "a=123&b=1234&c=1",
+			opts:     []Option{MaxValueLength(3)},
+			wantErr:  ParseError{Key: "b", Offset: 6, Reason: ReasonLimitExceeded, Limit: "MaxValueLength"},
+			want:     "a=123&c=1",
+			warnings: 1,
+		},
+		{
+			name:     "MaxValuesPerKey",
+			query:    "a=1&a=2&b=1&a=3&a=4",
+			opts:     []Option{MaxValuesPerKey(2)},
+			wantErr:  ParseError{Key: "a", Offset: 12, Reason: ReasonLimitExceeded, Limit: "MaxValuesPerKey"},
+			want:     "a=1&a=2&b=1",
+			warnings: 2,
+		},
+		{
+			name:     "MaxValuesPerKey with commas",
+			query:    "a=1,2,3",
+			opts:     []Option{MaxValuesPerKey(2), Comma()},
+			wantErr:  ParseError{Key: "a", Offset: 0, Reason: ReasonLimitExceeded, Limit: "MaxValuesPerKey"},
+			want:     "a=1&a=2",
+			warnings: 1,
+		},
+		{
+			name:     "MaxValuesPerKey with indices",
+			query:    "a[0]=1&a[0]=2&a[1]=3&a[1]=4",
+			opts:     []Option{MaxValuesPerKey(2)},
+			wantErr:  ParseError{Key: "a[1]", Offset: 14, Reason: ReasonLimitExceeded, Limit: "MaxValuesPerKey"},
+			want:     "a=1&a=2",
+			warnings: 2,
+		},
+		{
+			name:     "MaxValuesPerKey with nested indices",
+			query:    "a[0][0]=1&a[1]=2&a=3&a[b]=4",
+			opts:     []Option{MaxValuesPerKey(2)},
+			wantErr:  ParseError{Key: "a", Offset: 17, Reason: ReasonLimitExceeded, Limit: "MaxValuesPerKey"},
+			want:     "a=1&a=2&a[b]=4",
+			warnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.query, tt.opts...)
+			if tt.wantErr.Reason == 0 {
+				if err != nil {
+					t.Fatalf("New() error = %v, want nil", err)
+				}
+			} else {
+				var pErr *ParseError
+				if !errors.As(err, &pErr) {
+					t.Fatalf("New() error = %v, want *ParseError", err)
+				}
+				if *pErr != tt.wantErr {
+					t.Errorf("New() error = %+v, want %+v", *pErr, tt.wantErr)
+				}
+				if !errors.Is(err, ErrLimitExceeded) {
+					t.Errorf("errors.Is(%v, ErrLimitExceeded) = false", err)
+				}
+			}
+
+			q, err := New(tt.query, append(tt.opts, Lenient())...)
+			if err != nil {
+				t.Fatalf("New() error = %v, want nil", err)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("QS.String() = %v, want %v", got, tt.want)
+			}
+			if got := len(q.Warnings()); got != tt.warnings {
+				t.Errorf("len(QS.Warnings()) = %v, want %v", got, tt.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package qs
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// field describes a single struct field that can be decoded from or
+// encoded to a QS. The index is the path of field indices that leads to the
+// field, which is longer than one element for fields promoted from embedded
+// structs.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// cachedFields returns the fields of the struct type t, computing them on
+// the first call.
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]field)
+}
+
+// typeFields walks the struct type t breadth first and returns every field
+// that should be visible to the encoder and decoder. Fields of untagged
+// embedded structs are promoted to the parent. When two fields share the same
+// name, the one closest to the top level wins.
+func typeFields(t reflect.Type) []field {
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	fields := make([]field, 0)
+	names := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+	current := []level{{typ: t}}
+
+	for len(current) > 0 {
+		next := make([]level, 0)
+		for _, l := range current {
+			if visited[l.typ] {
+				continue
+			}
+			visited[l.typ] = true
+
+			for i := 0; i < l.typ.NumField(); i++ {
+				sf := l.typ.Field(i)
+				tag := sf.Tag.Get("qs")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts := parseTag(tag)
+				index := make([]int, len(l.index)+1)
+				copy(index, l.index)
+				index[len(l.index)] = i
+
+				if sf.Anonymous && name == "" {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						// Unexported embedded pointers cannot be allocated.
+						if sf.PkgPath != "" {
+							continue
+						}
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						next = append(next, level{typ: ft, index: index})
+						continue
+					}
+				}
+
+				if sf.PkgPath != "" {
+					continue
+				}
+
+				if name == "" {
+					name = sf.Name
+				}
+				if names[name] {
+					continue
+				}
+				names[name] = true
+
+				fields = append(fields, field{
+					name:      name,
+					index:     index,
+					omitEmpty: opts.contains("omitempty"),
+				})
+			}
+		}
+		current = next
+	}
+
+	return fields
+}
+
+// fieldByIndex returns the nested field of v at the given index, allocating
+// any nil embedded struct pointers along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// tagOptions is the comma separated list of options following the name in a
+// qs struct tag.
+type tagOptions string
+
+// parseTag splits a struct tag into its name and options.
+func parseTag(tag string) (string, tagOptions) {
+	if i := strings.Index(tag, ","); i != -1 {
+		return tag[:i], tagOptions(tag[i+1:])
+	}
+	return tag, ""
+}
+
+func (o tagOptions) contains(name string) bool {
+	if o == "" {
+		return false
+	}
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
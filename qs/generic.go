@@ -0,0 +1,39 @@
+package qs
+
+// Get retrieves the value at the given path converted to T. T may be any
+// numeric type, bool, string, time.Duration, time.Time, a type with a
+// registered converter, or a type whose pointer implements
+// encoding.TextUnmarshaler. The conversion rules are the same as the ones
+// used by Decode. An error wrapping ErrNotFound is returned if no
+// values exist at the path, and a *ConversionError if the value cannot be
+// converted.
+//
+//	page, err := qs.Get[int](q, "page")
+func Get[T any](q *QS, path ...string) (T, error) {
+	var v T
+	if err := q.getE(&v, path); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// GetOr retrieves the value at the given path converted to T as with Get. If
+// the path does not exist or the value cannot be converted, def is returned.
+func GetOr[T any](q *QS, def T, path ...string) T {
+	v, err := Get[T](q, path...)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// GetSlice retrieves all values at the given path converted to T as with
+// Get. A *ConversionError is returned if any value cannot be converted.
+func GetSlice[T any](q *QS, path ...string) ([]T, error) {
+	var s []T
+	if err := q.getAllE(&s, path); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,95 @@
+package qs
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type upper string
+
+func (u *upper) UnmarshalText(text []byte) error {
+	*u = upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestGet(t *testing.T) {
+	q, err := New("i=-3&u=7&f=1.25&b=true&s=abc&d=1m30s&ip=10.0.0.1&big=300")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		get     func() (interface{}, error)
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", get: func() (interface{}, error) { return Get[int](q, "i") }, want: -3},
+		{name: "int8", get: func() (interface{}, error) { return Get[int8](q, "i") }, want: int8(-3)},
+		{name: "uint16", get: func() (interface{}, error) { return Get[uint16](q, "u") }, want: uint16(7)},
+		{name: "float32", get: func() (interface{}, error) { return Get[float32](q, "f") }, want: float32(1.25)},
+		{name: "bool", get: func() (interface{}, error) { return Get[bool](q, "b") }, want: true},
+		{name: "string", get: func() (interface{}, error) { return Get[string](q, "s") }, want: "abc"},
+		{name: "Duration", get: func() (interface{}, error) { return Get[time.Duration](q, "d") }, want: 90 * time.Second},
+		{name: "TextUnmarshaler", get: func() (interface{}, error) { return Get[upper](q, "s") }, want: upper("ABC")},
+		{name: "net.IP", get: func() (interface{}, error) { return Get[net.IP](q, "ip") }, want: net.IPv4(10, 0, 0, 1)},
+		{name: "Overflow", get: func() (interface{}, error) { return Get[int8](q, "big") }, want: int8(0), wantErr: true},
+		{name: "Invalid", get: func() (interface{}, error) { return Get[time.Duration](q, "s") }, want: time.Duration(0), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Get[int](q, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	q, err := New("page=abc&size=5")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got := GetOr(q, 20, "size"); got != 5 {
+		t.Errorf("GetOr() = %v, want %v", got, 5)
+	}
+	if got := GetOr(q, 1, "page"); got != 1 {
+		t.Errorf("GetOr() = %v, want %v", got, 1)
+	}
+	if got := GetOr(q, 1, "missing"); got != 1 {
+		t.Errorf("GetOr() = %v, want %v", got, 1)
+	}
+}
+
+func TestGetSlice(t *testing.T) {
+	q, err := New("ids=1&ids=2&ids=3&bad=1&bad=x")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	got, err := GetSlice[int64](q, "ids")
+	if err != nil {
+		t.Fatalf("GetSlice() failed with err, %s", err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSlice() = %v, want %v", got, want)
+	}
+
+	var convErr *ConversionError
+	if _, err := GetSlice[int](q, "bad"); !errors.As(err, &convErr) || convErr.Value != "x" {
+		t.Errorf("GetSlice() error = %v, want a *ConversionError for x", err)
+	}
+}
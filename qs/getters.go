@@ -0,0 +1,462 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// ErrNotFound will be returned by the E getters when no values exist at the
+// provided path.
+var ErrNotFound = errors.New("not found")
+
+// GetE follows the provided keys and returns the first value at the end. An
+// error wrapping ErrNotFound is returned if no values exist at the path.
+func (q *QS) GetE(path ...string) (interface{}, error) {
+	_, vals, err := q.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return vals[0], nil
+}
+
+// GetAllE follows the provided keys and returns all values at the end. An
+// error wrapping ErrNotFound is returned if no values exist at the path.
+func (q *QS) GetAllE(path ...string) ([]interface{}, error) {
+	_, vals, err := q.lookup(path)
+	return vals, err
+}
+
+// GetStringE retrieves the value at the given path as a string. Values that
+// are not strings are formatted the same way as by String. An error wrapping
+// ErrNotFound is returned if no values exist at the path.
+func (q *QS) GetStringE(path ...string) (string, error) {
+	var s string
+	err := q.getE(&s, path)
+	return s, err
+}
+
+// GetStringSliceE retrieves all values at the given path as a string slice.
+// Values that are not strings are formatted the same way as by String. An
+// error wrapping ErrNotFound is returned if no values exist at the path.
+func (q *QS) GetStringSliceE(path ...string) ([]string, error) {
+	var s []string
+	err := q.getAllE(&s, path)
+	return s, err
+}
+
+// GetIntE retrieves the value at the given path as an int. An error wrapping
+// ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted or overflows an int.
+func (q *QS) GetIntE(path ...string) (int, error) {
+	var i int
+	err := q.getE(&i, path)
+	return i, err
+}
+
+// GetInt32E retrieves the value at the given path as an int32. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted or overflows an int32.
+func (q *QS) GetInt32E(path ...string) (int32, error) {
+	var i int32
+	err := q.getE(&i, path)
+	return i, err
+}
+
+// GetInt64E retrieves the value at the given path as an int64. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetInt64E(path ...string) (int64, error) {
+	var i int64
+	err := q.getE(&i, path)
+	return i, err
+}
+
+// GetFloat32E retrieves the value at the given path as a float32. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted or overflows a float32.
+func (q *QS) GetFloat32E(path ...string) (float32, error) {
+	var f float32
+	err := q.getE(&f, path)
+	return f, err
+}
+
+// GetFloat64E retrieves the value at the given path as a float64. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetFloat64E(path ...string) (float64, error) {
+	var f float64
+	err := q.getE(&f, path)
+	return f, err
+}
+
+// GetBoolE retrieves the value at the given path as a bool. An error wrapping
+// ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetBoolE(path ...string) (bool, error) {
+	var b bool
+	err := q.getE(&b, path)
+	return b, err
+}
+
+// GetUint retrieves the value at the given path as a uint. If the
+// value cannot be converted, 0 is returned.
+func (q *QS) GetUint(path ...string) uint {
+	v, _ := q.GetUintE(path...)
+	return v
+}
+
+// GetUintE retrieves the value at the given path as a uint. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetUintE(path ...string) (uint, error) {
+	var v uint
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetUint64 retrieves the value at the given path as a uint64. If the
+// value cannot be converted, 0 is returned.
+func (q *QS) GetUint64(path ...string) uint64 {
+	v, _ := q.GetUint64E(path...)
+	return v
+}
+
+// GetUint64E retrieves the value at the given path as a uint64. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetUint64E(path ...string) (uint64, error) {
+	var v uint64
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetDuration retrieves the value at the given path as a time.Duration.
+// Integers are treated as nanoseconds, and strings are parsed with
+// time.ParseDuration. If the value cannot be converted, 0 is returned.
+func (q *QS) GetDuration(path ...string) time.Duration {
+	v, _ := q.GetDurationE(path...)
+	return v
+}
+
+// GetDurationE retrieves the value at the given path as a time.Duration. An
+// error wrapping ErrNotFound is returned if no values exist at the path, and
+// a *ConversionError if the value cannot be converted.
+func (q *QS) GetDurationE(path ...string) (time.Duration, error) {
+	var v time.Duration
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetTime retrieves the value at the given path as a time.Time. Strings are
+// parsed using the TimeLayouts option. If the value cannot be converted, the
+// zero time is returned.
+func (q *QS) GetTime(path ...string) time.Time {
+	v, _ := q.GetTimeE(path...)
+	return v
+}
+
+// GetTimeE retrieves the value at the given path as a time.Time. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetTimeE(path ...string) (time.Time, error) {
+	var v time.Time
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetIP retrieves the value at the given path as a net.IP. If the
+// value cannot be converted, nil is returned.
+func (q *QS) GetIP(path ...string) net.IP {
+	v, _ := q.GetIPE(path...)
+	return v
+}
+
+// GetIPE retrieves the value at the given path as a net.IP. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetIPE(path ...string) (net.IP, error) {
+	var v net.IP
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetAddr retrieves the value at the given path as a netip.Addr. If the
+// value cannot be converted, the zero Addr is returned.
+func (q *QS) GetAddr(path ...string) netip.Addr {
+	v, _ := q.GetAddrE(path...)
+	return v
+}
+
+// GetAddrE retrieves the value at the given path as a netip.Addr. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetAddrE(path ...string) (netip.Addr, error) {
+	var v netip.Addr
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetURL retrieves the value at the given path as a *url.URL. If the
+// value cannot be converted, nil is returned.
+func (q *QS) GetURL(path ...string) *url.URL {
+	v, _ := q.GetURLE(path...)
+	return v
+}
+
+// GetURLE retrieves the value at the given path as a *url.URL. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetURLE(path ...string) (*url.URL, error) {
+	var v *url.URL
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetBigInt retrieves the value at the given path as a *big.Int. A prefix of
+// 0x, 0o or 0b selects the base. If the value cannot be converted, nil is
+// returned.
+func (q *QS) GetBigInt(path ...string) *big.Int {
+	v, _ := q.GetBigIntE(path...)
+	return v
+}
+
+// GetBigIntE retrieves the value at the given path as a *big.Int. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetBigIntE(path ...string) (*big.Int, error) {
+	var v *big.Int
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetBigRat retrieves the value at the given path as a *big.Rat. If the
+// value cannot be converted, nil is returned.
+func (q *QS) GetBigRat(path ...string) *big.Rat {
+	v, _ := q.GetBigRatE(path...)
+	return v
+}
+
+// GetBigRatE retrieves the value at the given path as a *big.Rat. An error
+// wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if the value cannot be converted.
+func (q *QS) GetBigRatE(path ...string) (*big.Rat, error) {
+	var v *big.Rat
+	err := q.getE(&v, path)
+	return v, err
+}
+
+// GetIntSlice retrieves all values at the given path as a []int. If any
+// value cannot be converted, nil is returned.
+func (q *QS) GetIntSlice(path ...string) []int {
+	v, _ := q.GetIntSliceE(path...)
+	return v
+}
+
+// GetIntSliceE retrieves all values at the given path as a []int. An
+// error wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if any value cannot be converted.
+func (q *QS) GetIntSliceE(path ...string) ([]int, error) {
+	var v []int
+	err := q.getAllE(&v, path)
+	return v, err
+}
+
+// GetFloat64Slice retrieves all values at the given path as a []float64. If any
+// value cannot be converted, nil is returned.
+func (q *QS) GetFloat64Slice(path ...string) []float64 {
+	v, _ := q.GetFloat64SliceE(path...)
+	return v
+}
+
+// GetFloat64SliceE retrieves all values at the given path as a []float64. An
+// error wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if any value cannot be converted.
+func (q *QS) GetFloat64SliceE(path ...string) ([]float64, error) {
+	var v []float64
+	err := q.getAllE(&v, path)
+	return v, err
+}
+
+// GetBoolSlice retrieves all values at the given path as a []bool. If any
+// value cannot be converted, nil is returned.
+func (q *QS) GetBoolSlice(path ...string) []bool {
+	v, _ := q.GetBoolSliceE(path...)
+	return v
+}
+
+// GetBoolSliceE retrieves all values at the given path as a []bool. An
+// error wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if any value cannot be converted.
+func (q *QS) GetBoolSliceE(path ...string) ([]bool, error) {
+	var v []bool
+	err := q.getAllE(&v, path)
+	return v, err
+}
+
+// GetDurationSlice retrieves all values at the given path as a
+// []time.Duration. If any value cannot be converted, nil is returned.
+func (q *QS) GetDurationSlice(path ...string) []time.Duration {
+	v, _ := q.GetDurationSliceE(path...)
+	return v
+}
+
+// GetDurationSliceE retrieves all values at the given path as a
+// []time.Duration. An error wrapping ErrNotFound is returned if no values
+// exist at the path, and a *ConversionError if any value cannot be converted.
+func (q *QS) GetDurationSliceE(path ...string) ([]time.Duration, error) {
+	var v []time.Duration
+	err := q.getAllE(&v, path)
+	return v, err
+}
+
+// GetTimeSlice retrieves all values at the given path as a []time.Time. If any
+// value cannot be converted, nil is returned.
+func (q *QS) GetTimeSlice(path ...string) []time.Time {
+	v, _ := q.GetTimeSliceE(path...)
+	return v
+}
+
+// GetTimeSliceE retrieves all values at the given path as a []time.Time. An
+// error wrapping ErrNotFound is returned if no values exist at the path, and a
+// *ConversionError if any value cannot be converted.
+func (q *QS) GetTimeSliceE(path ...string) ([]time.Time, error) {
+	var v []time.Time
+	err := q.getAllE(&v, path)
+	return v, err
+}
+
+// GetMap returns the subkeys at the given path as a map of plain Go values,
+// following the same rules as decoding into an empty interface. A subkey with
+// a single value maps to that value, one with several values maps to a
+// []interface{}, and one with subkeys maps to a nested map[string]interface{}.
+// Values at a node that also has subkeys are stored under the empty key,
+// unless the node has a subkey named "" e.g. a=1&a[][b]=2, which takes
+// precedence and leaves the values out. If no path is provided, the whole
+// tree is returned. If the path does not exist or has no subkeys, nil is
+// returned.
+func (q *QS) GetMap(path ...string) map[string]interface{} {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := q.findOrRoot(path)
+	if n == nil || len(n.Children) == 0 {
+		return nil
+	}
+
+	return nodeInterface(n).(map[string]interface{})
+}
+
+// GetStringMap returns the first value of each subkey at the given path as a
+// string e.g. a[x]=1&a[y]=2 becomes map[x:1 y:2] for the path a. Subkeys
+// without values are skipped. If no path is provided, the top level keys are
+// used. If the path does not exist, nil is returned.
+func (q *QS) GetStringMap(path ...string) map[string]string {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := q.findOrRoot(path)
+	if n == nil {
+		return nil
+	}
+
+	c := q.converter()
+	m := make(map[string]string, len(n.Children))
+	for k, child := range n.Children {
+		if len(child.Values) > 0 {
+			m[k] = c.toString(child.Values[0])
+		}
+	}
+
+	return m
+}
+
+// GetStringMapSlice returns all values of each subkey at the given path as a
+// string slice e.g. labels[env]=prod&labels[team]=a&labels[team]=b becomes
+// map[env:[prod] team:[a b]] for the path labels. Subkeys without values are
+// skipped. If no path is provided, the top level keys are used. If the path
+// does not exist, nil is returned.
+func (q *QS) GetStringMapSlice(path ...string) map[string][]string {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := q.findOrRoot(path)
+	if n == nil {
+		return nil
+	}
+
+	c := q.converter()
+	m := make(map[string][]string, len(n.Children))
+	for k, child := range n.Children {
+		if len(child.Values) > 0 {
+			m[k] = c.toStrings(child.Values)
+		}
+	}
+
+	return m
+}
+
+// findOrRoot is like find, but returns the top level node if no path is
+// provided. The caller must hold the lock.
+func (q *QS) findOrRoot(path []string) *node {
+	if len(path) == 0 {
+		return q.root()
+	}
+	return q.find(q.splitPath(path)...)
+}
+
+// lookup returns the split path and a copy of the values at the end of it. An
+// error wrapping ErrNotFound is returned if the path holds no values.
+func (q *QS) lookup(path []string) ([]string, []interface{}, error) {
+	path = q.splitPath(path)
+
+	q.mutex.RLock()
+	n := q.find(path...)
+	var vals []interface{}
+	if n != nil {
+		vals = append(vals, n.Values...)
+	}
+	q.mutex.RUnlock()
+
+	if len(vals) == 0 {
+		return path, nil, fmt.Errorf("%w: %s", ErrNotFound, formatPath(path))
+	}
+
+	return path, vals, nil
+}
+
+// getE converts the first value at path into the type pointed to by dst.
+func (q *QS) getE(dst interface{}, path []string) error {
+	path, vals, err := q.lookup(path)
+	if err != nil {
+		return err
+	}
+
+	return q.converter().decodeValue(vals[0], reflect.ValueOf(dst).Elem(), path)
+}
+
+// getAllE converts every value at path into the element type of the slice
+// pointed to by dst.
+func (q *QS) getAllE(dst interface{}, path []string) error {
+	path, vals, err := q.lookup(path)
+	if err != nil {
+		return err
+	}
+
+	c := q.converter()
+	v := reflect.ValueOf(dst).Elem()
+	s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+	for i, raw := range vals {
+		if err := c.decodeValue(raw, s.Index(i), path); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package qs
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQS_GetE(t *testing.T) {
+	q, err := New("page=abc&size=0&big=3000000000&on=true&f=1.5&tags=a&tags=b&a[b]=1")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	q.Set([]interface{}{"x", struct{}{}}, "mixed")
+
+	tests := []struct {
+		name    string
+		get     func() (interface{}, error)
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name: "Int",
+			get:  func() (interface{}, error) { return q.GetIntE("size") },
+			want: 0,
+		},
+		{
+			name: "Nested",
+			get:  func() (interface{}, error) { return q.GetIntE("a", "b") },
+			want: 1,
+		},
+		{
+			name:    "Missing",
+			get:     func() (interface{}, error) { return q.GetIntE("missing") },
+			want:    0,
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "Node without values",
+			get:     func() (interface{}, error) { return q.GetIntE("a") },
+			want:    0,
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "Invalid int",
+			get:     func() (interface{}, error) { return q.GetIntE("page") },
+			want:    0,
+			wantErr: &ConversionError{},
+		},
+		{
+			name:    "Overflow",
+			get:     func() (interface{}, error) { return q.GetInt32E("big") },
+			want:    int32(0),
+			wantErr: &ConversionError{},
+		},
+		{
+			name: "Int64",
+			get:  func() (interface{}, error) { return q.GetInt64E("big") },
+			want: int64(3000000000),
+		},
+		{
+			name: "Float32",
+			get:  func() (interface{}, error) { return q.GetFloat32E("f") },
+			want: float32(1.5),
+		},
+		{
+			name: "Float64",
+			get:  func() (interface{}, error) { return q.GetFloat64E("f") },
+			want: 1.5,
+		},
+		{
+			name: "Bool",
+			get:  func() (interface{}, error) { return q.GetBoolE("on") },
+			want: true,
+		},
+		{
+			name:    "Invalid bool",
+			get:     func() (interface{}, error) { return q.GetBoolE("page") },
+			want:    false,
+			wantErr: &ConversionError{},
+		},
+		{
+			name: "String",
+			get:  func() (interface{}, error) { return q.GetStringE("page") },
+			want: "abc",
+		},
+		{
+			name: "String slice",
+			get:  func() (interface{}, error) { return q.GetStringSliceE("tags") },
+			want: []string{"a", "b"},
+		},
+		{
+			name: "Formatted string slice",
+			get:  func() (interface{}, error) { return q.GetStringSliceE("mixed") },
+			want: []string{"x", "{}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			switch target := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			case *ConversionError:
+				if !errors.As(err, &target) {
+					t.Fatalf("error = %v, want a *ConversionError", err)
+				}
+				if errors.Is(err, ErrNotFound) {
+					t.Errorf("conversion error matches ErrNotFound")
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_GetE_ConversionError(t *testing.T) {
+	q, err := New("a.b=abc", PathDelimiter("."), AllowDots())
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	_, err = q.GetIntE("a.b")
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("QS.GetIntE() error = %v, want a *ConversionError", err)
+	}
+	if !reflect.DeepEqual(convErr.Path, []string{"a", "b"}) || convErr.Value != "abc" {
+		t.Errorf("ConversionError = %+v, want path [a b] and value abc", convErr)
+	}
+}
+
+func TestQS_GetAllE(t *testing.T) {
+	q, err := New("a=1&a=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	got, err := q.GetAllE("a")
+	if err != nil {
+		t.Fatalf("QS.GetAllE() failed with err, %s", err)
+	}
+	if want := []interface{}{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.GetAllE() = %v, want %v", got, want)
+	}
+
+	if _, err := q.GetE("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("QS.GetE() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestQS_GetTypes(t *testing.T) {
+	q, err := New("u=7&neg=-1&d=1m30s&t=2024-03-01T10:00:00Z&day=2024-03-01&unix=1700000000" +
+		"&ip=10.0.0.1&addr=::1&url=https://example.com/a%3Fb%3D1&bi=0x10&br=3/4" +
+		"&ints=1&ints=2&fs=1.5&fs=2&bs=true&bs=0&ds=1s&ds=2ms&ts=2024-03-01&ts=0")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		get     func() (interface{}, error)
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "Uint", get: func() (interface{}, error) { return q.GetUintE("u") }, want: uint(7)},
+		{name: "Negative uint", get: func() (interface{}, error) { return q.GetUint64E("neg") }, want: uint64(0), wantErr: true},
+		{name: "Duration", get: func() (interface{}, error) { return q.GetDurationE("d") }, want: 90 * time.Second},
+		{name: "RFC3339", get: func() (interface{}, error) { return q.GetTimeE("t") }, want: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "Date", get: func() (interface{}, error) { return q.GetTimeE("day") }, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Unix", get: func() (interface{}, error) { return q.GetTimeE("unix") }, want: time.Unix(1700000000, 0).UTC()},
+		{name: "Invalid time", get: func() (interface{}, error) { return q.GetTimeE("ip") }, want: time.Time{}, wantErr: true},
+		{name: "IP", get: func() (interface{}, error) { return q.GetIPE("ip") }, want: net.IPv4(10, 0, 0, 1)},
+		{name: "Addr", get: func() (interface{}, error) { return q.GetAddrE("addr") }, want: netip.IPv6Loopback()},
+		{name: "Invalid addr", get: func() (interface{}, error) { return q.GetAddrE("u") }, want: netip.Addr{}, wantErr: true},
+		{name: "URL", get: func() (interface{}, error) { return q.GetURLE("url") }, want: &url.URL{Scheme: "https", Host: "example.com", Path: "/a", RawQuery: "b=1"}},
+		{name: "BigInt", get: func() (interface{}, error) { return q.GetBigIntE("bi") }, want: big.NewInt(16)},
+		{name: "BigRat", get: func() (interface{}, error) { return q.GetBigRatE("br") }, want: big.NewRat(3, 4)},
+		{name: "Invalid BigInt", get: func() (interface{}, error) { return q.GetBigIntE("br") }, want: (*big.Int)(nil), wantErr: true},
+		{name: "Int slice", get: func() (interface{}, error) { return q.GetIntSliceE("ints") }, want: []int{1, 2}},
+		{name: "Float64 slice", get: func() (interface{}, error) { return q.GetFloat64SliceE("fs") }, want: []float64{1.5, 2}},
+		{name: "Bool slice", get: func() (interface{}, error) { return q.GetBoolSliceE("bs") }, want: []bool{true, false}},
+		{name: "Duration slice", get: func() (interface{}, error) { return q.GetDurationSliceE("ds") }, want: []time.Duration{time.Second, 2 * time.Millisecond}},
+		{name: "Time slice", get: func() (interface{}, error) { return q.GetTimeSliceE("ts") }, want: []time.Time{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Unix(0, 0).UTC()}},
+		{name: "Invalid int slice", get: func() (interface{}, error) { return q.GetIntSliceE("fs") }, want: []int(nil), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_GetTime_Layouts(t *testing.T) {
+	q, err := New("a=01/02/2024&b=2024-01-02", TimeLayouts("01/02/2006"))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if got := q.GetTime("a"); !got.Equal(want) {
+		t.Errorf("QS.GetTime() = %v, want %v", got, want)
+	}
+	if got := q.GetTime("b"); !got.IsZero() {
+		t.Errorf("QS.GetTime() = %v, want the zero time", got)
+	}
+}
+
+func TestQS_GetMap(t *testing.T) {
+	q, err := New("a[x]=1&a[y]=2&a[y]=3&a[z][w]=4&a[z]=5&b=6&labels[env]=prod&labels[team]=a&labels[team]=b&labels[empty][c]=7")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	want := map[string]interface{}{
+		"x": "1",
+		"y": []interface{}{"2", "3"},
+		"z": map[string]interface{}{"": "5", "w": "4"},
+	}
+	if got := q.GetMap("a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.GetMap() = %v, want %v", got, want)
+	}
+	if got := q.GetMap("b"); got != nil {
+		t.Errorf("QS.GetMap() = %v, want nil", got)
+	}
+	if got := q.GetMap("missing"); got != nil {
+		t.Errorf("QS.GetMap() = %v, want nil", got)
+	}
+	if got := q.GetMap(); len(got) != 3 || got["b"] != "6" {
+		t.Errorf("QS.GetMap() = %v, want the whole tree", got)
+	}
+
+	wantStrings := map[string]string{"env": "prod", "team": "a"}
+	if got := q.GetStringMap("labels"); !reflect.DeepEqual(got, wantStrings) {
+		t.Errorf("QS.GetStringMap() = %v, want %v", got, wantStrings)
+	}
+
+	wantSlices := map[string][]string{"env": {"prod"}, "team": {"a", "b"}}
+	if got := q.GetStringMapSlice("labels"); !reflect.DeepEqual(got, wantSlices) {
+		t.Errorf("QS.GetStringMapSlice() = %v, want %v", got, wantSlices)
+	}
+	if got := q.GetStringMapSlice("missing"); got != nil {
+		t.Errorf("QS.GetStringMapSlice() = %v, want nil", got)
+	}
+}
+
+func TestQS_GetMap_EmptyKey(t *testing.T) {
+	q, err := New("a=1&a[][b]=2&c=3&c[d]=4")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	// The subkey named "" takes precedence over the values of a.
+	want := map[string]interface{}{"": map[string]interface{}{"b": "2"}}
+	if got := q.GetMap("a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.GetMap() = %v, want %v", got, want)
+	}
+
+	var got map[string]interface{}
+	if err := q.Decode(&got); err != nil {
+		t.Fatalf("QS.Decode() failed with err, %s", err)
+	}
+	wantDecoded := map[string]interface{}{
+		"a": map[string]interface{}{"": map[string]interface{}{"b": "2"}},
+		"c": map[string]interface{}{"": "3", "d": "4"},
+	}
+	if !reflect.DeepEqual(got, wantDecoded) {
+		t.Errorf("QS.Decode() = %v, want %v", got, wantDecoded)
+	}
+}
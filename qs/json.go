@@ -0,0 +1,290 @@
+package qs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// ErrJSONKeyConflict will be returned by MarshalJSON when a node has both
+// values and a subkey named JSONValuesKey.
+var ErrJSONKeyConflict = errors.New("JSON values key clashes with a subkey")
+
+// FromJSON converts a JSON object into a new QS. Any provided options are
+// applied to the returned QS. See UnmarshalJSON for how the object is mapped
+// onto the tree.
+//
+//	q, err := qs.FromJSON([]byte(`{"a":{"b":["1","2"]},"c":true}`))
+//	q.EncodedString() // a[b]=1&a[b]=2&c=true
+func FromJSON(data []byte, opts ...Option) (*QS, error) {
+	q, err := New("", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// MarshalJSON implements json.Marshaler. The tree is written as a JSON
+// object, with subkeys becoming nested objects in the same order as String.
+// A node with several values becomes an array, and a node with a single value
+// becomes that value, or an array if JSONArrays is set. The values of a node
+// that also has subkeys are written under JSONValuesKey. An error wrapping
+// ErrJSONKeyConflict is returned if such a node also has a subkey named
+// JSONValuesKey, since the object would otherwise hold a duplicate key.
+//
+// Values of types with a registered EncodeFunc are written as JSON strings
+// holding the same text as String. Other values are encoded with
+// json.Marshal.
+func (q *QS) MarshalJSON() ([]byte, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var b bytes.Buffer
+	if err := q.writeJSONObject(&b, q.root(), nil, q.printer(false)); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (q *QS) writeJSONObject(b *bytes.Buffer, n *node, path []string, p printer) error {
+	b.WriteByte('{')
+
+	first := true
+	writeKey := func(key string) {
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+	}
+
+	if len(n.Values) > 0 {
+		if _, ok := n.Children[q.JSONValuesKey]; ok {
+			return fmt.Errorf("%w: %q at %q", ErrJSONKeyConflict, q.JSONValuesKey, formatPath(path))
+		}
+
+		writeKey(q.JSONValuesKey)
+		if err := q.writeJSONValues(b, n.Values, p); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range p.keys(n) {
+		writeKey(k)
+		if err := q.writeJSONNode(b, n.Children[k], appendPath(path, k), p); err != nil {
+			return err
+		}
+	}
+
+	b.WriteByte('}')
+	return nil
+}
+
+func (q *QS) writeJSONNode(b *bytes.Buffer, n *node, path []string, p printer) error {
+	if len(n.Children) > 0 {
+		return q.writeJSONObject(b, n, path, p)
+	}
+	if len(n.Values) == 0 {
+		b.WriteString("null")
+		return nil
+	}
+	return q.writeJSONValues(b, n.Values, p)
+}
+
+func (q *QS) writeJSONValues(b *bytes.Buffer, vals []interface{}, p printer) error {
+	if len(vals) == 1 && !q.JSONArrays {
+		return writeJSONValue(b, vals[0], p)
+	}
+
+	b.WriteByte('[')
+	for i, val := range vals {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := writeJSONValue(b, val, p); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(']')
+
+	return nil
+}
+
+func writeJSONValue(b *bytes.Buffer, val interface{}, p printer) error {
+	if val != nil {
+		if conv, ok := p.conv.lookup(reflect.TypeOf(val)); ok && conv.Encode != nil {
+			val = p.conv.format(val)
+		}
+	}
+
+	v, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	b.Write(v)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the tree with the
+// provided JSON object. Keys become subkeys in the order they appear and
+// nested objects become nested subkeys. Strings, numbers and bools become
+// string values, while nulls are skipped. Scalars in an array become the
+// values of a single key, and objects or arrays within an array become
+// indexed subkeys numbered from 0. A key equal to JSONValuesKey holds the
+// values of the enclosing node rather than a subkey, unless it holds an
+// object or an array of objects. A QS that was not created with New can be
+// used as well. On a view returned by Sub, only the subtree of the view is
+// replaced.
+func (q *QS) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("qs: cannot unmarshal JSON %v into a QS, want an object", tok)
+	}
+
+	root := newNode("")
+	if err := q.readJSONObject(dec, root); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("qs: unexpected data after the top level JSON object")
+	}
+
+	if q.mutex == nil {
+		q.mutex = &sync.RWMutex{}
+	}
+
+	q.lock()
+	defer q.unlock()
+
+	if q.origin == nil {
+		q.Values = root
+		return nil
+	}
+
+	n := q.root()
+	n.Values, n.Children, n.Order = root.Values, root.Children, root.Order
+
+	return nil
+}
+
+// readJSONObject reads the members of an object into n. The opening brace
+// has already been read.
+func (q *QS) readJSONObject(dec *json.Decoder, n *node) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		child := n.child(key)
+		if err := q.readJSONValue(dec, child); err != nil {
+			return err
+		}
+
+		// Values under JSONValuesKey belong to n, but anything with subkeys
+		// is kept as a real subkey so that no level is lost.
+		if key == q.JSONValuesKey && len(child.Children) == 0 {
+			n.Values = append(n.Values, child.Values...)
+			child.Values = nil
+		}
+		if len(child.Values) == 0 && len(child.Children) == 0 {
+			n.removeChild(key)
+		}
+	}
+
+	_, err := dec.Token()
+	return err
+}
+
+// readJSONValue reads the next JSON value into n.
+func (q *QS) readJSONValue(dec *json.Decoder, n *node) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		return q.readJSONObject(dec, n)
+	case json.Delim('['):
+		return q.readJSONArray(dec, n)
+	}
+
+	if s, ok := jsonScalar(tok); ok {
+		n.Values = append(n.Values, s)
+	}
+
+	return nil
+}
+
+// readJSONArray reads the elements of an array into n. The opening bracket
+// has already been read.
+func (q *QS) readJSONArray(dec *json.Decoder, n *node) error {
+	index := 0
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var child *node
+		switch tok {
+		case json.Delim('{'):
+			child = n.child(strconv.Itoa(index))
+			err = q.readJSONObject(dec, child)
+		case json.Delim('['):
+			child = n.child(strconv.Itoa(index))
+			err = q.readJSONArray(dec, child)
+		default:
+			if s, ok := jsonScalar(tok); ok {
+				n.Values = append(n.Values, s)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(child.Values) == 0 && len(child.Children) == 0 {
+			n.removeChild(child.Key)
+			continue
+		}
+		index++
+	}
+
+	_, err := dec.Token()
+	return err
+}
+
+// jsonScalar returns the string form of a scalar JSON token. Nulls are
+// reported as not ok.
+func jsonScalar(tok json.Token) (string, bool) {
+	switch v := tok.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
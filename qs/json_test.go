@@ -0,0 +1,212 @@
+package qs
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestQS_MarshalJSON(t *testing.T) {
+	query := "b=1&a[y]=2&a[y]=3&a[x]=4&a=5&c[0][id]=6&c[1][id]=7"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "Default",
+			want: `{"b":"1","a":{"":"5","y":["2","3"],"x":"4"},"c":{"0":{"id":"6"},"1":{"id":"7"}}}`,
+		},
+		{
+			name: "Arrays",
+			opts: []Option{JSONArrays()},
+			want: `{"b":["1"],"a":{"":["5"],"y":["2","3"],"x":["4"]},"c":{"0":{"id":["6"]},"1":{"id":["7"]}}}`,
+		},
+		{
+			name: "Values key and sorted",
+			opts: []Option{JSONValuesKey("_"), SortKeys()},
+			want: `{"a":{"_":"5","x":"4","y":["2","3"]},"b":"1","c":{"0":{"id":"6"},"1":{"id":"7"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			got, err := json.Marshal(q)
+			if err != nil {
+				t.Fatalf("json.Marshal failed with err, %s", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_MarshalJSON_Values(t *testing.T) {
+	q, err := New("")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	q.Set([]interface{}{1, true, nil}, "a")
+
+	got, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal failed with err, %s", err)
+	}
+	if want := `{"a":[1,true,null]}`; string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+
+	q.Set([]interface{}{func() {}}, "a")
+	if _, err := json.Marshal(q); err == nil {
+		t.Errorf("json.Marshal() of a func value succeeded")
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Nested",
+			json: `{"z":"1","a":{"b":["x","y"],"c":{"d":2.50}},"e":true,"f":null}`,
+			want: "z=1&a[b]=x&a[b]=y&a[c][d]=2.50&e=true",
+		},
+		{
+			name: "Array of objects",
+			json: `{"items":[{"id":1},{},null,{"id":2},"x",[3,4]]}`,
+			want: "items=x&items[0][id]=1&items[1][id]=2&items[2]=3&items[2]=4",
+		},
+		{
+			name: "Values key",
+			json: `{"a":{"_":["1","2"],"b":"3"}}`,
+			opts: []Option{JSONValuesKey("_")},
+			want: "a=1&a=2&a[b]=3",
+		},
+		{
+			name: "Escaped",
+			json: `{"a b":"c&d"}`,
+			want: "a+b=c%26d",
+		},
+		{
+			name:    "Not an object",
+			json:    `["a"]`,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing data",
+			json:    `{}{}`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid",
+			json:    `{"a":}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := FromJSON([]byte(tt.json), tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := q.EncodedString(); got != tt.want {
+				t.Errorf("QS.EncodedString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_UnmarshalJSON_RoundTrip(t *testing.T) {
+	q1, err := New("b=1&a[y]=2&a[y]=3&a[x]=4&a=5&c[0][id]=6&c[1][id]=7")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	data, err := json.Marshal(q1)
+	if err != nil {
+		t.Fatalf("json.Marshal failed with err, %s", err)
+	}
+
+	// A zero QS is usable as the target.
+	var q2 QS
+	if err := json.Unmarshal(data, &q2); err != nil {
+		t.Fatalf("json.Unmarshal failed with err, %s", err)
+	}
+
+	if !reflect.DeepEqual(q1.Values, q2.Values) {
+		t.Errorf("round trip through %s changed the tree", data)
+	}
+}
+
+func TestQS_MarshalJSON_ValuesKeyConflict(t *testing.T) {
+	q, err := New("a=1&a[][b]=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if _, err := json.Marshal(q); !errors.Is(err, ErrJSONKeyConflict) {
+		t.Fatalf("json.Marshal() error = %v, want %v", err, ErrJSONKeyConflict)
+	}
+
+	q.JSONValuesKey = "_"
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal failed with err, %s", err)
+	}
+	if want := `{"a":{"_":"1","":{"b":"2"}}}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	q2, err := FromJSON(data, JSONValuesKey("_"))
+	if err != nil {
+		t.Fatalf("FromJSON failed with err, %s", err)
+	}
+	if !reflect.DeepEqual(q.Values, q2.Values) {
+		t.Errorf("round trip through %s changed the tree", data)
+	}
+	if got, want := q2.String(), "a=1&a[][b]=2"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestFromJSON_ValuesKeyObject(t *testing.T) {
+	q, err := FromJSON([]byte(`{"a":{"":{"b":"2"},"c":"3"},"d":{"":["4","5"]}}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed with err, %s", err)
+	}
+
+	if got, want := q.String(), "a[][b]=2&a[c]=3&d=4&d=5"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_MarshalJSON_Converters(t *testing.T) {
+	q, err := New("", Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	q.Set([]interface{}{money{Cents: 250}}, "price")
+	q.Set([]interface{}{orderID(4)}, "id")
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("json.Marshal failed with err, %s", err)
+	}
+	if want := `{"price":"2.50","id":"ord_4"}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
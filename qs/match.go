@@ -0,0 +1,204 @@
+package qs
+
+import (
+	"sort"
+	"strings"
+)
+
+// Match is a node selected by a wildcard path along with its full path.
+type Match struct {
+	Path   []string
+	Values []interface{}
+}
+
+// GetAllMatch returns the path and values of every node that holds values and
+// matches the provided path, in the same order as Walk. Besides plain keys,
+// the path may contain wildcards. A subkey of * matches any single subkey,
+// and a subkey of ** matches zero or more subkeys. Elsewhere, a * within a
+// subkey matches any run of characters, so utm_* matches any single subkey
+// starting with utm_.
+//
+// For example, GetAllMatch("items", "*", "id") returns the id of every item,
+// while GetAllMatch("**", "id") returns every id at any depth. If a
+// PathDelimiter is set, the first parameter is split on it as with the
+// other getters e.g. GetAllMatch("items.*.id"). If nothing matches, an empty
+// slice is returned.
+func (q *QS) GetAllMatch(path ...string) []Match {
+	matches := make([]Match, 0)
+	if len(path) == 0 {
+		return matches
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	q.match(path, func(path []string, n *node) {
+		if len(n.Values) > 0 {
+			matches = append(matches, Match{
+				Path:   path,
+				Values: append([]interface{}{}, n.Values...),
+			})
+		}
+	})
+
+	return matches
+}
+
+// match calls fn with the path of every node matching the wildcard path, in
+// the same order as Walk. The top level node is never matched. The caller
+// must hold the lock.
+func (q *QS) match(path []string, fn func(path []string, n *node)) {
+	pattern := trimPath(q.splitPath(path))
+	if len(pattern) == 0 {
+		return
+	}
+
+	matchNode(q.root(), nil, addState(nil, pattern, 0), pattern, fn)
+}
+
+// matchNode visits n, reached through path, and its subkeys. States holds
+// the positions in pattern that may come next after path was matched.
+func matchNode(n *node, path []string, states []int, pattern []string, fn func(path []string, n *node)) {
+	if len(states) == 0 {
+		return
+	}
+
+	if len(path) > 0 && states[len(states)-1] == len(pattern) {
+		fn(path, n)
+	}
+
+	for _, k := range n.keys() {
+		next := make([]int, 0, len(states))
+		for _, i := range states {
+			switch {
+			case i == len(pattern):
+			case pattern[i] == "**":
+				next = addState(next, pattern, i)
+			case matchSegment(pattern[i], k):
+				next = addState(next, pattern, i+1)
+			}
+		}
+
+		matchNode(n.Children[k], appendPath(path, k), next, pattern, fn)
+	}
+}
+
+// addState adds position i in pattern to the sorted set of states. A ** may
+// match no subkeys, so the positions after it are added as well.
+func addState(states []int, pattern []string, i int) []int {
+	for ; i <= len(pattern); i++ {
+		j := sort.SearchInts(states, i)
+		if j == len(states) || states[j] != i {
+			states = append(states, 0)
+			copy(states[j+1:], states[j:])
+			states[j] = i
+		}
+
+		if i == len(pattern) || pattern[i] != "**" {
+			break
+		}
+	}
+	return states
+}
+
+// matchSegment reports whether key matches a single subkey of a wildcard
+// path, where each * matches any run of characters.
+func matchSegment(pattern, key string) bool {
+	if strings.IndexByte(pattern, '*') == -1 {
+		return pattern == key
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i == -1 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+
+	return len(key) >= len(last) && strings.HasSuffix(key, last)
+}
+
+// SetMatch overwrites the values of every node matching the wildcard path
+// with the provided values. Wildcards are described by GetAllMatch. Unlike
+// Set, no nodes are created. The number of nodes set is returned.
+func (q *QS) SetMatch(vals []interface{}, path ...string) int {
+	q.lock()
+	defer q.unlock()
+
+	count := 0
+	q.match(path, func(path []string, n *node) {
+		n.Values = append([]interface{}{}, vals...)
+		count++
+	})
+
+	return count
+}
+
+// DeleteMatch removes every node matching the wildcard path along with its
+// subkeys, as with Delete. Wildcards are described by GetAllMatch. The number
+// of nodes removed is returned, not counting nodes removed as the subkeys of
+// another matched node.
+func (q *QS) DeleteMatch(path ...string) int {
+	q.lock()
+	defer q.unlock()
+
+	paths := make([][]string, 0)
+	q.match(path, func(path []string, n *node) {
+		paths = append(paths, path)
+	})
+
+	count := 0
+	for _, p := range paths {
+		if q.find(p...) != nil {
+			q.delete(p)
+			count++
+		}
+	}
+
+	return count
+}
+
+// TransformFunc is the type of function called by Transform for every
+// matched node. It receives a copy of the path and values of the node and
+// returns the new values.
+type TransformFunc func(path []string, values []interface{}) []interface{}
+
+// Transform replaces the values of every node that holds values and matches
+// the wildcard path with the result of fn. Wildcards are described by
+// GetAllMatch. If fn returns no values, the node is removed as with Delete,
+// unless it has subkeys. The tree is locked for the duration of the call, so
+// fn must not use the QS. The number of nodes transformed is returned.
+func (q *QS) Transform(fn TransformFunc, path ...string) int {
+	q.lock()
+	defer q.unlock()
+
+	empty := make([][]string, 0)
+	count := 0
+	q.match(path, func(path []string, n *node) {
+		if len(n.Values) == 0 {
+			return
+		}
+
+		n.Values = fn(append([]string{}, path...), append([]interface{}{}, n.Values...))
+		if len(n.Values) == 0 {
+			empty = append(empty, path)
+		}
+		count++
+	})
+
+	for _, p := range empty {
+		if n := q.find(p...); n != nil && len(n.Children) == 0 {
+			q.delete(p)
+		}
+	}
+
+	return count
+}
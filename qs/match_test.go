@@ -0,0 +1,203 @@
+package qs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQS_GetAllMatch(t *testing.T) {
+	query := "items[0][id]=1&items[0][tags][0]=x&items[1][id]=2&id=3&utm_source=a&utm_medium=b&ref=c"
+	tests := []struct {
+		name string
+		opts []Option
+		path []string
+		want []Match
+	}{
+		{
+			name: "Single level",
+			path: []string{"items", "*", "id"},
+			want: []Match{
+				{Path: []string{"items", "0", "id"}, Values: []interface{}{"1"}},
+				{Path: []string{"items", "1", "id"}, Values: []interface{}{"2"}},
+			},
+		},
+		{
+			name: "Recursive",
+			path: []string{"**", "id"},
+			want: []Match{
+				{Path: []string{"items", "0", "id"}, Values: []interface{}{"1"}},
+				{Path: []string{"items", "1", "id"}, Values: []interface{}{"2"}},
+				{Path: []string{"id"}, Values: []interface{}{"3"}},
+			},
+		},
+		{
+			name: "Trailing recursive",
+			path: []string{"items", "0", "**"},
+			want: []Match{
+				{Path: []string{"items", "0", "id"}, Values: []interface{}{"1"}},
+				{Path: []string{"items", "0", "tags"}, Values: []interface{}{"x"}},
+			},
+		},
+		{
+			name: "Repeated recursive",
+			path: []string{"**", "**", "tags"},
+			want: []Match{
+				{Path: []string{"items", "0", "tags"}, Values: []interface{}{"x"}},
+			},
+		},
+		{
+			name: "Glob",
+			path: []string{"utm_*"},
+			want: []Match{
+				{Path: []string{"utm_source"}, Values: []interface{}{"a"}},
+				{Path: []string{"utm_medium"}, Values: []interface{}{"b"}},
+			},
+		},
+		{
+			name: "Path delimiter",
+			opts: []Option{PathDelimiter(".")},
+			path: []string{"items.*.id"},
+			want: []Match{
+				{Path: []string{"items", "0", "id"}, Values: []interface{}{"1"}},
+				{Path: []string{"items", "1", "id"}, Values: []interface{}{"2"}},
+			},
+		},
+		{
+			name: "No match",
+			path: []string{"items", "*", "name"},
+			want: []Match{},
+		},
+		{
+			name: "No path",
+			want: []Match{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.GetAllMatch(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAllMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matchSegment(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{pattern: "a", key: "a", want: true},
+		{pattern: "a", key: "ab", want: false},
+		{pattern: "*", key: "", want: true},
+		{pattern: "utm_*", key: "utm_source", want: true},
+		{pattern: "utm_*", key: "utm", want: false},
+		{pattern: "*_id", key: "user_id", want: true},
+		{pattern: "a*b*c", key: "abbc", want: true},
+		{pattern: "a*b*c", key: "acb", want: false},
+		{pattern: "ab*ba", key: "aba", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.key, func(t *testing.T) {
+			if got := matchSegment(tt.pattern, tt.key); got != tt.want {
+				t.Errorf("matchSegment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_SetMatch(t *testing.T) {
+	q, err := New("filters[0][enabled]=true&filters[0][name]=a&filters[1][enabled]=false&enabled=true")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got := q.SetMatch([]interface{}{"false"}, "filters", "*", "enabled"); got != 2 {
+		t.Errorf("QS.SetMatch() = %v, want %v", got, 2)
+	}
+	if got := q.SetMatch([]interface{}{"x"}, "missing", "*"); got != 0 {
+		t.Errorf("QS.SetMatch() = %v, want %v", got, 0)
+	}
+
+	want := "filters[0][enabled]=false&filters[0][name]=a&filters[1][enabled]=false&enabled=true"
+	if got := q.String(); got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_DeleteMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  []string
+		want  string
+		count int
+	}{
+		{
+			name:  "Glob",
+			path:  []string{"utm_*"},
+			want:  "a[utm_x]=1&b=2",
+			count: 2,
+		},
+		{
+			name:  "Prunes parents",
+			path:  []string{"a", "*"},
+			want:  "utm_source=x&b=2&utm_medium=y",
+			count: 1,
+		},
+		{
+			name:  "Nested matches",
+			path:  []string{"**"},
+			want:  "",
+			count: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New("utm_source=x&a[utm_x]=1&b=2&utm_medium=y")
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.DeleteMatch(tt.path...); got != tt.count {
+				t.Errorf("QS.DeleteMatch() = %v, want %v", got, tt.count)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("QS.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_Transform(t *testing.T) {
+	q, err := New("a[b]=1&a[b][c]=2&a[d]=3&e=4", PathDelimiter("."))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	paths := make([][]string, 0)
+	count := q.Transform(func(path []string, values []interface{}) []interface{} {
+		paths = append(paths, path)
+		if path[len(path)-1] == "c" {
+			return []interface{}{"x"}
+		}
+		return nil
+	}, "a.**")
+
+	if count != 3 {
+		t.Errorf("QS.Transform() = %v, want %v", count, 3)
+	}
+
+	wantPaths := [][]string{{"a", "b"}, {"a", "b", "c"}, {"a", "d"}}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("QS.Transform() called fn with %v, want %v", paths, wantPaths)
+	}
+
+	if got, want := q.String(), "a[b][c]=x&e=4"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
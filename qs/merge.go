@@ -0,0 +1,114 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMergeConflict will be returned by Merge when using MergeError and both
+// query strings hold values at the same path.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeStrategy determines what Merge does when both query strings hold
+// values at the same path.
+type MergeStrategy int
+
+const (
+	// MergeOverwrite replaces the existing values with the incoming values.
+	MergeOverwrite MergeStrategy = iota
+	// MergeKeepExisting keeps the existing values and discards the incoming
+	// values.
+	MergeKeepExisting
+	// MergeAppend appends the incoming values to the existing values.
+	MergeAppend
+	// MergeError aborts the merge and returns an error wrapping
+	// ErrMergeConflict. Nothing is merged in this case.
+	MergeError
+)
+
+// Merge recursively merges the tree of other into q. Paths that only exist in
+// other are added to q, after any existing subkeys. When both query strings
+// hold values at the same path, the strategy decides the outcome. Other is
+// not modified.
+//
+// Other is copied under its own read lock before q is locked for writing, so
+// merging two query strings into each other concurrently cannot deadlock.
+func (q *QS) Merge(other *QS, strategy MergeStrategy) error {
+	if other == nil {
+		return nil
+	}
+
+	other.mutex.RLock()
+	src := other.root().clone()
+	other.mutex.RUnlock()
+
+	q.lock()
+	defer q.unlock()
+
+	if strategy == MergeError {
+		if path, ok := findConflict(q.root(), src, nil); ok {
+			return fmt.Errorf("%w at %s", ErrMergeConflict, formatPath(path))
+		}
+	}
+
+	mergeNode(q.root(), src, strategy)
+
+	return nil
+}
+
+// Merge combines the provided query strings into a new QS, with later query
+// strings taking precedence as with MergeOverwrite. The new QS uses the
+// options of the first non-nil query string. None of the provided query
+// strings are modified.
+func Merge(qss ...*QS) *QS {
+	var merged *QS
+	for _, q := range qss {
+		if q == nil {
+			continue
+		}
+		if merged == nil {
+			merged = q.emptyCopy()
+		}
+		_ = merged.Merge(q, MergeOverwrite)
+	}
+
+	if merged == nil {
+		merged, _ = New("")
+	}
+
+	return merged
+}
+
+func mergeNode(dst, src *node, strategy MergeStrategy) {
+	if len(src.Values) > 0 {
+		switch {
+		case len(dst.Values) == 0 || strategy == MergeOverwrite:
+			dst.Values = src.Values
+		case strategy == MergeAppend:
+			dst.Values = append(dst.Values, src.Values...)
+		}
+	}
+
+	for _, k := range src.keys() {
+		mergeNode(dst.child(k), src.Children[k], strategy)
+	}
+}
+
+// findConflict returns the first path at which both dst and src hold values.
+func findConflict(dst, src *node, path []string) ([]string, bool) {
+	if len(path) > 0 && len(dst.Values) > 0 && len(src.Values) > 0 {
+		return path, true
+	}
+
+	for _, k := range src.keys() {
+		child, ok := dst.Children[k]
+		if !ok {
+			continue
+		}
+		if p, ok := findConflict(child, src.Children[k], appendPath(path, k)); ok {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
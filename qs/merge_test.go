@@ -0,0 +1,134 @@
+package qs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestQS_Merge(t *testing.T) {
+	base := "a[b]=1&a[c]=2&d=3"
+	other := "a[b]=4&a[e]=5&d=6&f=7"
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Overwrite",
+			strategy: MergeOverwrite,
+			want:     "a[b]=4&a[c]=2&a[e]=5&d=6&f=7",
+		},
+		{
+			name:     "Keep existing",
+			strategy: MergeKeepExisting,
+			want:     "a[b]=1&a[c]=2&a[e]=5&d=3&f=7",
+		},
+		{
+			name:     "Append",
+			strategy: MergeAppend,
+			want:     "a[b]=1&a[b]=4&a[c]=2&a[e]=5&d=3&d=6&f=7",
+		},
+		{
+			name:     "Error",
+			strategy: MergeError,
+			want:     "a[b]=1&a[c]=2&d=3",
+			wantErr:  ErrMergeConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q1, err := New(base)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+			q2, err := New(other)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			err = q1.Merge(q2, tt.strategy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("QS.Merge() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := q1.String(); got != tt.want {
+				t.Errorf("QS.String() = %v, want %v", got, tt.want)
+			}
+			if got := q2.String(); got != other {
+				t.Errorf("QS.Merge() modified other: %v", got)
+			}
+		})
+	}
+}
+
+func TestQS_Merge_NoConflict(t *testing.T) {
+	q1, _ := New("a[b]=1")
+	q2, _ := New("a[c]=2&a[b][d]=3")
+
+	if err := q1.Merge(q2, MergeError); err != nil {
+		t.Fatalf("QS.Merge() error = %v, want nil", err)
+	}
+	if got, want := q1.String(), "a[b]=1&a[b][d]=3&a[c]=2"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Merge_Self(t *testing.T) {
+	q, _ := New("a=1")
+
+	if err := q.Merge(q, MergeAppend); err != nil {
+		t.Fatalf("QS.Merge() error = %v, want nil", err)
+	}
+	if got, want := q.String(), "a=1&a=1"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Merge_Concurrent(t *testing.T) {
+	q1, _ := New("a=1")
+	q2, _ := New("b=2")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = q1.Merge(q2, MergeOverwrite)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = q2.Merge(q1, MergeOverwrite)
+		}()
+	}
+	wg.Wait()
+
+	if got, want := q1.String(), "a=1&b=2"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	defaults, _ := New("page=1&size=20&sort=name", PathDelimiter("."))
+	saved, _ := New("size=50&filter[status]=open")
+	request, _ := New("page=3")
+
+	got := Merge(nil, defaults, saved, nil, request)
+	if want := "page=3&size=50&sort=name&filter[status]=open"; got.String() != want {
+		t.Errorf("Merge().String() = %v, want %v", got.String(), want)
+	}
+	if got.PathDelimiter != "." {
+		t.Errorf("Merge() did not keep the options of the first query string")
+	}
+	if got.GetString("filter.status") != "open" {
+		t.Errorf("Merge() result is not usable with the copied options")
+	}
+	if defaults.String() != "page=1&size=20&sort=name" {
+		t.Errorf("Merge() modified its input: %v", defaults.String())
+	}
+
+	if empty := Merge(); empty == nil || empty.String() != "" {
+		t.Errorf("Merge() with no input should return an empty QS")
+	}
+}
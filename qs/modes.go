@@ -0,0 +1,205 @@
+package qs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ConversionMode determines the rules used to convert values into numbers,
+// bools and durations.
+type ConversionMode int
+
+const (
+	// ConvertLenient uses the rules of the cast library. For example, "0x10"
+	// is converted to the int 16 and the float 1.9 is truncated to 1.
+	ConvertLenient ConversionMode = iota
+	// ConvertStrict only accepts values that represent the requested type
+	// exactly. Integers must be written in base 10 without a fractional part
+	// or trailing characters, floats must be finite and written in base 10,
+	// bools must be "true" or "false", and durations must be parsable by
+	// time.ParseDuration. Numeric values are rejected if they do not fit the
+	// requested type.
+	ConvertStrict
+	// ConvertForms is like ConvertStrict, but bools also accept the values
+	// sent by HTML forms e.g. "on" and "off", "1" and "0", or "yes" and "no".
+	// Bools are case insensitive in this mode.
+	ConvertForms
+)
+
+// toBool converts raw to a bool following the conversion mode.
+func (c converter) toBool(raw interface{}) (bool, error) {
+	if c.mode == ConvertLenient {
+		return cast.ToBoolE(raw)
+	}
+
+	if b, ok := raw.(bool); ok {
+		return b, nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return false, fmt.Errorf("unable to convert %#v of type %T to bool", raw, raw)
+	}
+
+	if c.mode == ConvertForms {
+		switch strings.ToLower(s) {
+		case "true", "on", "yes", "1":
+			return true, nil
+		case "false", "off", "no", "0":
+			return false, nil
+		}
+	} else {
+		switch s {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("%q is not a bool", s)
+}
+
+// toInt64 converts raw to an int64 following the conversion mode.
+func (c converter) toInt64(raw interface{}) (int64, error) {
+	if c.mode == ConvertLenient {
+		return cast.ToInt64E(raw)
+	}
+
+	switch v := raw.(type) {
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := c.toUint64(v)
+		if u > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows int64", u)
+		}
+		return int64(u), nil
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	}
+
+	return 0, fmt.Errorf("unable to convert %#v of type %T to int64", raw, raw)
+}
+
+// toUint64 converts raw to a uint64 following the conversion mode.
+func (c converter) toUint64(raw interface{}) (uint64, error) {
+	if c.mode == ConvertLenient {
+		return cast.ToUint64E(raw)
+	}
+
+	switch v := raw.(type) {
+	case string:
+		return strconv.ParseUint(v, 10, 64)
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case int, int8, int16, int32, int64, float32, float64:
+		i, err := c.toInt64(v)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return 0, fmt.Errorf("%d is negative", i)
+		}
+		return uint64(i), nil
+	}
+
+	return 0, fmt.Errorf("unable to convert %#v of type %T to uint64", raw, raw)
+}
+
+// toFloat64 converts raw to a float64 following the conversion mode.
+func (c converter) toFloat64(raw interface{}) (float64, error) {
+	if c.mode == ConvertLenient {
+		return cast.ToFloat64E(raw)
+	}
+
+	var f float64
+	switch v := raw.(type) {
+	case string:
+		digits := strings.TrimLeft(v, "+-")
+		if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+			return 0, fmt.Errorf("%q is not a base 10 number", v)
+		}
+
+		var err error
+		if f, err = strconv.ParseFloat(v, 64); err != nil {
+			return 0, err
+		}
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	case int, int8, int16, int32, int64:
+		i, _ := c.toInt64(v)
+		f = float64(i)
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := c.toUint64(v)
+		f = float64(u)
+	default:
+		return 0, fmt.Errorf("unable to convert %#v of type %T to float64", raw, raw)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%v is not finite", f)
+	}
+
+	return f, nil
+}
+
+// toDuration converts raw to a time.Duration following the conversion mode.
+// Integers are treated as nanoseconds.
+func (c converter) toDuration(raw interface{}) (time.Duration, error) {
+	if c.mode == ConvertLenient {
+		return cast.ToDurationE(raw)
+	}
+
+	switch v := raw.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	}
+
+	i, err := c.toInt64(raw)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(i), nil
+}
+
+// floatToInt64 converts f to an int64, rejecting fractions and values out of
+// range.
+func floatToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("%v has a fractional part", f)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v overflows int64", f)
+	}
+	return int64(f), nil
+}
@@ -0,0 +1,195 @@
+package qs
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConversionMode(t *testing.T) {
+	q, err := New("frac=1.9&hex=0x10&octal=010&trail=12abc&big=300&neg=-1" +
+		"&yes=yes&on=ON&one=1&t=true&inf=Inf&f=2.5&hexf=-0x1p4&d=5&ds=5s")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	q.Set([]interface{}{1.9}, "float")
+	q.Set([]interface{}{2.0}, "whole")
+
+	type result struct {
+		v  interface{}
+		ok bool
+	}
+	get := func(mode ConversionMode, fn func(*QS) (interface{}, error)) result {
+		c := q.Clone()
+		c.Conversion = mode
+		v, err := fn(c)
+		return result{v, err == nil}
+	}
+
+	tests := []struct {
+		name    string
+		fn      func(*QS) (interface{}, error)
+		lenient result
+		strict  result
+		forms   result
+	}{
+		{
+			name:    "Fractional string",
+			fn:      func(q *QS) (interface{}, error) { return q.GetIntE("frac") },
+			lenient: result{0, false},
+			strict:  result{0, false},
+			forms:   result{0, false},
+		},
+		{
+			name:    "Fractional float",
+			fn:      func(q *QS) (interface{}, error) { return q.GetIntE("float") },
+			lenient: result{1, true},
+			strict:  result{0, false},
+			forms:   result{0, false},
+		},
+		{
+			name:    "Whole float",
+			fn:      func(q *QS) (interface{}, error) { return q.GetIntE("whole") },
+			lenient: result{2, true},
+			strict:  result{2, true},
+			forms:   result{2, true},
+		},
+		{
+			name:    "Hex",
+			fn:      func(q *QS) (interface{}, error) { return q.GetIntE("hex") },
+			lenient: result{16, true},
+			strict:  result{0, false},
+			forms:   result{0, false},
+		},
+		{
+			name:    "Octal",
+			fn:      func(q *QS) (interface{}, error) { return q.GetIntE("octal") },
+			lenient: result{8, true},
+			strict:  result{10, true},
+			forms:   result{10, true},
+		},
+		{
+			name:    "Trailing garbage",
+			fn:      func(q *QS) (interface{}, error) { return q.GetInt64E("trail") },
+			lenient: result{int64(0), false},
+			strict:  result{int64(0), false},
+			forms:   result{int64(0), false},
+		},
+		{
+			name:    "Overflow",
+			fn:      func(q *QS) (interface{}, error) { return Get[int8](q, "big") },
+			lenient: result{int8(0), false},
+			strict:  result{int8(0), false},
+			forms:   result{int8(0), false},
+		},
+		{
+			name:    "Negative uint",
+			fn:      func(q *QS) (interface{}, error) { return q.GetUintE("neg") },
+			lenient: result{uint(0), false},
+			strict:  result{uint(0), false},
+			forms:   result{uint(0), false},
+		},
+		{
+			name:    "Yes",
+			fn:      func(q *QS) (interface{}, error) { return q.GetBoolE("yes") },
+			lenient: result{false, false},
+			strict:  result{false, false},
+			forms:   result{true, true},
+		},
+		{
+			name:    "On",
+			fn:      func(q *QS) (interface{}, error) { return q.GetBoolE("on") },
+			lenient: result{false, false},
+			strict:  result{false, false},
+			forms:   result{true, true},
+		},
+		{
+			name:    "One",
+			fn:      func(q *QS) (interface{}, error) { return q.GetBoolE("one") },
+			lenient: result{true, true},
+			strict:  result{false, false},
+			forms:   result{true, true},
+		},
+		{
+			name:    "True",
+			fn:      func(q *QS) (interface{}, error) { return q.GetBoolE("t") },
+			lenient: result{true, true},
+			strict:  result{true, true},
+			forms:   result{true, true},
+		},
+		{
+			name:    "Infinity",
+			fn:      func(q *QS) (interface{}, error) { return q.GetFloat64E("inf") },
+			lenient: result{math.Inf(1), true},
+			strict:  result{float64(0), false},
+			forms:   result{float64(0), false},
+		},
+		{
+			name:    "Float",
+			fn:      func(q *QS) (interface{}, error) { return q.GetFloat32E("f") },
+			lenient: result{float32(2.5), true},
+			strict:  result{float32(2.5), true},
+			forms:   result{float32(2.5), true},
+		},
+		{
+			name:    "Hex float",
+			fn:      func(q *QS) (interface{}, error) { return q.GetFloat64E("hexf") },
+			lenient: result{float64(-16), true},
+			strict:  result{float64(0), false},
+			forms:   result{float64(0), false},
+		},
+		{
+			name:    "Integer duration",
+			fn:      func(q *QS) (interface{}, error) { return q.GetDurationE("d") },
+			lenient: result{5 * time.Nanosecond, true},
+			strict:  result{time.Duration(0), false},
+			forms:   result{time.Duration(0), false},
+		},
+		{
+			name:    "Duration",
+			fn:      func(q *QS) (interface{}, error) { return q.GetDurationE("ds") },
+			lenient: result{5 * time.Second, true},
+			strict:  result{5 * time.Second, true},
+			forms:   result{5 * time.Second, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, m := range []struct {
+				name string
+				mode ConversionMode
+				want result
+			}{
+				{"lenient", ConvertLenient, tt.lenient},
+				{"strict", ConvertStrict, tt.strict},
+				{"forms", ConvertForms, tt.forms},
+			} {
+				if got := get(m.mode, tt.fn); !reflect.DeepEqual(got, m.want) {
+					t.Errorf("%s: got %#v, want %#v", m.name, got, m.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConversionMode_Getters(t *testing.T) {
+	q, err := New("page=1.9&remember=on", Conversion(ConvertForms))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got := q.GetInt("page"); got != 0 {
+		t.Errorf("QS.GetInt() = %v, want %v", got, 0)
+	}
+	if got := q.GetBool("remember"); !got {
+		t.Errorf("QS.GetBool() = %v, want %v", got, true)
+	}
+
+	var form struct {
+		Remember bool `qs:"remember"`
+	}
+	if err := q.Decode(&form); err != nil || !form.Remember {
+		t.Errorf("QS.Decode() = %+v, %v, want remember to be true", form, err)
+	}
+}
@@ -3,11 +3,12 @@ package qs
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
-
-	"github.com/spf13/cast"
 )
 
 var (
@@ -17,6 +18,12 @@ var (
 	// ErrUnbalanced will be returned when brackets in the query string are
 	// unbalanced e.g. a[[b] or a[b]].
 	ErrUnbalanced = errors.New("brackets are unbalanced")
+	// ErrDepthExceeded will be returned when a key has more subkeys than
+	// MaxDepth allows and StrictDepth is set.
+	ErrDepthExceeded = errors.New("max depth exceeded")
+	// ErrLimitExceeded will be returned when the query string exceeds one of
+	// the configured limits.
+	ErrLimitExceeded = errors.New("limit exceeded")
 )
 
 // QS holds both the raw query string as well as the parsed data structure.
@@ -30,20 +37,107 @@ type QS struct {
 	Values *node
 	// MaxDepth is the number of subkeys to parse before stopping (Default: 5)
 	MaxDepth int
+	// StrictDepth causes New to return an error rather than truncating keys
+	// that are deeper than MaxDepth. (Default: false)
+	StrictDepth bool
 	// PathDelimiter is the string that separates keys in the path. Providing
 	// a delimiter overrides the default behavior of supplying a path as
 	// variadic arguments to Get, Add, Set, etc. If this option is set, then
 	// any variadic methods will instead split the first parameter on this
 	// value and treat the resulting slice as the path elements. (Default: "")
 	PathDelimiter string
-
-	mutex *sync.RWMutex
+	// ParameterLimit is the maximum number of key/value pairs that are
+	// parsed. A value less than or equal to zero means no limit. (Default: 0)
+	ParameterLimit int
+	// MaxNodes is the maximum number of nodes that may be created while
+	// parsing, not counting the root. A value less than or equal to zero means
+	// no limit. (Default: 0)
+	MaxNodes int
+	// MaxKeyLength is the maximum length in bytes of a raw key. A value less
+	// than or equal to zero means no limit. (Default: 0)
+	MaxKeyLength int
+	// MaxValueLength is the maximum length in bytes of a raw value. A value
+	// less than or equal to zero means no limit. (Default: 0)
+	MaxValueLength int
+	// MaxValuesPerKey is the maximum number of values that may be parsed for
+	// a single key. Values of indexed subkeys count towards the key that they
+	// are collapsed into e.g. a[0]=1&a[1]=2 holds two values for a. A value
+	// less than or equal to zero means no limit. (Default: 0)
+	MaxValuesPerKey int
+	// Lenient causes New to work around malformed pairs rather than failing.
+	// Each problem is recorded and can be retrieved with Warnings.
+	// (Default: false)
+	Lenient bool
+	// ArrayLimit is the largest subkey index that is parsed as an array index
+	// e.g. a[0]=x&a[1]=y. Larger indices are kept as regular subkeys. A
+	// negative limit disables array parsing. (Default: 20)
+	ArrayLimit int
+	// ArrayFormat is the style used to write keys with multiple values when
+	// stringifying. (Default: ArrayRepeat)
+	ArrayFormat ArrayStyle
+	// Comma causes values to be split on commas when parsing e.g. a=1,2 is
+	// parsed the same as a=1&a=2. An encoded comma (%2C) is not split on.
+	// (Default: false)
+	Comma bool
+	// AllowDots causes a '.' in a key to be parsed as a subkey delimiter e.g.
+	// a.b.c is parsed the same as a[b][c]. An encoded dot (%2E) is not treated
+	// as a delimiter. This is unrelated to PathDelimiter. (Default: false)
+	AllowDots bool
+	// DotNotation causes subkeys to be written using dots rather than
+	// brackets when stringifying e.g. a.b.c instead of a[b][c]. Dots within
+	// keys are encoded by EncodedString. (Default: false)
+	DotNotation bool
+	// PrefixKeys causes a QS returned by Sub or Extract to write its keys with
+	// the full path from the original top level when stringifying, rather
+	// than relative to its own root. (Default: false)
+	PrefixKeys bool
+	// TimeLayouts are the layouts used to parse time.Time values, tried in
+	// order. The TimeUnix layout parses integers as seconds since the Unix
+	// epoch. (Default: DefaultTimeLayouts)
+	TimeLayouts []string
+	// Conversion is the set of rules used to convert values into numbers,
+	// bools and durations by the typed getters and Decode.
+	// (Default: ConvertLenient)
+	Conversion ConversionMode
+	// Converters holds the functions used to convert values of custom types
+	// for this QS. They take precedence over types registered with
+	// RegisterType. (Default: nil)
+	Converters map[reflect.Type]Converter
+	// JSONArrays causes MarshalJSON to write nodes with a single value as an
+	// array rather than as the value itself. (Default: false)
+	JSONArrays bool
+	// JSONValuesKey is the key under which MarshalJSON writes the values of a
+	// node that also has subkeys. UnmarshalJSON reads values from the same key.
+	// (Default: "")
+	JSONValuesKey string
+	// Sort orders the keys at each level when stringifying. If nil, keys are
+	// written in the order they were first parsed or added. (Default: nil)
+	Sort func(a, b string) bool
+
+	mutex    *sync.RWMutex
+	warnings []Warning
+	// nodes counts the nodes created while parsing when MaxNodes is set.
+	nodes int
+	// values counts the values parsed for each key, after collapsing arrays,
+	// when MaxValuesPerKey is set.
+	values map[string]int
+	// prefix is the path of Values from the original top level for a QS
+	// returned by Sub or Extract.
+	prefix []string
+	// origin is the QS that a view returned by Sub reads from, and subPath is
+	// the path of the view's root within the tree of origin. The root is
+	// looked up on every call, since it only exists in the tree while it holds
+	// values or subkeys.
+	origin  *QS
+	subPath []string
 }
 
 type node struct {
 	Key      string
 	Values   []interface{}
 	Children map[string]*node
+	// Order holds the keys of Children in insertion order.
+	Order []string
 }
 
 // Option is a functional option used to configure a new QS.
@@ -60,6 +154,16 @@ func MaxDepth(d int) Option {
 	}
 }
 
+// StrictDepth sets the StrictDepth property of a QS struct. If set, then a key
+// with more subkeys than MaxDepth causes New to return a *ParseError with the
+// reason ReasonDepthExceeded rather than keeping the rest of the key as the
+// final subkey.
+func StrictDepth() Option {
+	return func(qs *QS) {
+		qs.StrictDepth = true
+	}
+}
+
 // PathDelimiter sets the PathDelimiter property of a QS struct. Providing
 // a delimiter overrides the default behavior of supplying a path as
 // variadic arguments to Get, Add, Set, etc. If this option is set, then
@@ -71,82 +175,428 @@ func PathDelimiter(d string) Option {
 	}
 }
 
-// New produces a new QS data structure. Before parsing subkeys, the raw
-// query string is processed by net/url's ParseQuery function. This function
-// unescapes any URL encoding.
+// ParameterLimit sets the ParameterLimit property of a QS struct. New returns
+// a *ParseError with the reason ReasonLimitExceeded if the query string holds
+// more than n key/value pairs. In lenient mode, the first n pairs are parsed
+// and the rest are dropped with a single warning.
+func ParameterLimit(n int) Option {
+	return func(qs *QS) {
+		qs.ParameterLimit = n
+	}
+}
+
+// MaxNodes sets the MaxNodes property of a QS struct. New returns a
+// *ParseError with the reason ReasonLimitExceeded if parsing would create more
+// than n nodes in the tree. In lenient mode, pairs that would exceed the limit
+// are dropped.
+func MaxNodes(n int) Option {
+	return func(qs *QS) {
+		qs.MaxNodes = n
+	}
+}
+
+// MaxKeyLength sets the MaxKeyLength property of a QS struct. New returns a
+// *ParseError with the reason ReasonLimitExceeded if a raw key is longer than
+// n bytes. In lenient mode, pairs with long keys are dropped.
+func MaxKeyLength(n int) Option {
+	return func(qs *QS) {
+		qs.MaxKeyLength = n
+	}
+}
+
+// MaxValueLength sets the MaxValueLength property of a QS struct. New returns
+// a *ParseError with the reason ReasonLimitExceeded if a raw value is longer
+// than n bytes. In lenient mode, pairs with long values are dropped.
+func MaxValueLength(n int) Option {
+	return func(qs *QS) {
+		qs.MaxValueLength = n
+	}
+}
+
+// MaxValuesPerKey sets the MaxValuesPerKey property of a QS struct. New
+// returns a *ParseError with the reason ReasonLimitExceeded if more than n
+// values are parsed for a single key. In lenient mode, the first n values are
+// kept and the rest are dropped.
+func MaxValuesPerKey(n int) Option {
+	return func(qs *QS) {
+		qs.MaxValuesPerKey = n
+	}
+}
+
+// Lenient sets the Lenient property of a QS struct. In lenient mode, New
+// continues past malformed pairs and builds the tree from the rest of the
+// query string. Invalid escape sequences are kept as literal text, keys with
+// unbalanced brackets are kept as a single literal key, and keys deeper than
+// MaxDepth are truncated even if StrictDepth is set. Every problem is recorded
+// as a Warning.
+func Lenient() Option {
+	return func(qs *QS) {
+		qs.Lenient = true
+	}
+}
+
+// ArrayLimit sets the ArrayLimit property of a QS struct. Numeric subkeys no
+// greater than the limit are parsed as array indices. Indexed values are
+// collected in index order as the values of the parent key, and sparse
+// indices are compacted e.g.
+//		a[2]=y&a[0]=x&a[9]=z => []string{"x", "y", "z"}
+// Indexed subkeys that have subkeys of their own are kept as subkeys, but are
+// renumbered from zero in index order. Indices above the limit are kept as
+// regular subkeys, which prevents a[99999999] from producing a huge array. A
+// negative limit disables array parsing entirely.
+func ArrayLimit(n int) Option {
+	return func(qs *QS) {
+		qs.ArrayLimit = n
+	}
+}
+
+// ArrayFormat sets the ArrayFormat property of a QS struct. When stringifying,
+// keys with multiple values are written in the given style.
+func ArrayFormat(f ArrayStyle) Option {
+	return func(qs *QS) {
+		qs.ArrayFormat = f
+	}
+}
+
+// Comma sets the Comma property of a QS struct. When parsing, values are
+// split on unencoded commas and each part is added as a separate value.
+// Combine with ArrayFormat(ArrayComma) to round trip comma separated values.
+func Comma() Option {
+	return func(qs *QS) {
+		qs.Comma = true
+	}
+}
+
+// AllowDots sets the AllowDots property of a QS struct. When parsing, a '.'
+// outside of brackets is treated as the start of a new subkey in addition to
+// '['. Each dot counts towards MaxDepth.
+func AllowDots() Option {
+	return func(qs *QS) {
+		qs.AllowDots = true
+	}
+}
+
+// DotNotation sets the DotNotation property of a QS struct. When
+// stringifying, subkeys are joined with dots rather than wrapped in brackets.
+func DotNotation() Option {
+	return func(qs *QS) {
+		qs.DotNotation = true
+	}
+}
+
+// PrefixKeys sets the PrefixKeys property of a QS struct. A QS returned by Sub
+// or Extract then writes its keys with the full path from the original top
+// level e.g. filter[status]=open rather than status=open. The setting is
+// inherited by any QS returned by Sub or Extract.
+func PrefixKeys() Option {
+	return func(qs *QS) {
+		qs.PrefixKeys = true
+	}
+}
+
+// TimeLayouts sets the TimeLayouts property of a QS struct. The layouts are
+// used in order by GetTime and the other getters to parse time.Time values.
+// Pass TimeUnix to accept seconds since the Unix epoch.
+func TimeLayouts(layouts ...string) Option {
+	return func(qs *QS) {
+		qs.TimeLayouts = layouts
+	}
+}
+
+// Conversion sets the Conversion property of a QS struct. Use ConvertStrict
+// to reject values that do not represent the requested type exactly, or
+// ConvertForms to also accept HTML form values such as "on" for bools.
+func Conversion(mode ConversionMode) Option {
+	return func(qs *QS) {
+		qs.Conversion = mode
+	}
+}
+
+// Converters adds the provided converters to the Converters property of a QS
+// struct. They are used to convert values of custom types for this QS only.
+// See RegisterType for details.
+func Converters(convs map[reflect.Type]Converter) Option {
+	return func(qs *QS) {
+		if qs.Converters == nil {
+			qs.Converters = make(map[reflect.Type]Converter, len(convs))
+		}
+		for t, conv := range convs {
+			qs.Converters[t] = conv
+		}
+	}
+}
+
+// JSONArrays sets the JSONArrays property of a QS struct. Every node with
+// values is then written as a JSON array by MarshalJSON, even if it only has
+// a single value.
+func JSONArrays() Option {
+	return func(qs *QS) {
+		qs.JSONArrays = true
+	}
+}
+
+// JSONValuesKey sets the JSONValuesKey property of a QS struct. MarshalJSON
+// writes the values of a node that also has subkeys under this key, and
+// UnmarshalJSON reads them back from it.
+func JSONValuesKey(key string) Option {
+	return func(qs *QS) {
+		qs.JSONValuesKey = key
+	}
+}
+
+// Sort sets the Sort property of a QS struct. When stringifying, the keys at
+// each level of the tree are ordered using the provided less function rather
+// than insertion order.
+func Sort(less func(a, b string) bool) Option {
+	return func(qs *QS) {
+		qs.Sort = less
+	}
+}
+
+// SortKeys is shorthand for Sort with a lexicographic comparison of keys.
+func SortKeys() Option {
+	return Sort(func(a, b string) bool { return a < b })
+}
+
+// New produces a new QS data structure. The raw query string is split into
+// key/value pairs in source order. Subkeys are parsed from each raw key before
+// the key and value are unescaped, so an encoded bracket (%5B or %5D) is
+// treated as part of a key rather than as a subkey delimiter. Numeric subkeys
+// are parsed as array indices as described by ArrayLimit.
 //
-// An error will be returned if the provided query string cannot be parsed.
+// A *ParseError will be returned if the provided query string cannot be
+// parsed. The error describes the offending key and where in the query string
+// the problem was found.
 func New(rawQuery string, opts ...Option) (*QS, error) {
 	qs := &QS{
-		RawQuery: rawQuery,
-		Values:   newNode(""),
-		MaxDepth: 5,
-		mutex:    &sync.RWMutex{},
+		RawQuery:   rawQuery,
+		Values:     newNode(""),
+		MaxDepth:   5,
+		ArrayLimit: 20,
+		mutex:      &sync.RWMutex{},
 	}
 
 	for _, opt := range opts {
 		opt(qs)
 	}
 
-	pq, err := url.ParseQuery(rawQuery)
+	for i, p := range scan(rawQuery, qs.ParameterLimit) {
+		if qs.ParameterLimit > 0 && i == qs.ParameterLimit {
+			if err := qs.fail(limitError(p, "ParameterLimit"), true); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if err := qs.parsePair(p); err != nil {
+			return nil, err
+		}
+	}
+
+	collapseArrays(qs.Values, qs.ArrayLimit)
+
+	return qs, nil
+}
+
+// parsePair parses a single key/value pair and adds it to the tree.
+func (q *QS) parsePair(p pair) error {
+	if q.MaxKeyLength > 0 && len(p.key) > q.MaxKeyLength {
+		return q.fail(limitError(p, "MaxKeyLength"), true)
+	}
+	if q.MaxValueLength > 0 && len(p.value) > q.MaxValueLength {
+		return q.fail(limitError(p, "MaxValueLength"), true)
+	}
+
+	if _, err := unescape(p.key); err != nil {
+		pErr := &ParseError{Key: p.key, Offset: p.offset + int(err.(escapeError)), Reason: ReasonBadEscape}
+		if err := q.fail(pErr, false); err != nil {
+			return err
+		}
+	}
+
+	keys, err := q.parseKey(p.key)
 	if err != nil {
-		return nil, ErrInvalidQS
+		pErr := err.(*ParseError)
+		pErr.Offset += p.offset
+		if err := q.fail(pErr, false); err != nil {
+			return err
+		}
+
+		// Keys with unbalanced brackets are kept as a single literal key. Keys
+		// that are too deep keep the truncated subkeys from parseKey.
+		if pErr.Reason == ReasonUnbalanced {
+			keys = []string{p.key}
+		}
 	}
 
-	if qs.PathDelimiter != "" {
-		defer func(del string) { qs.PathDelimiter = del }(qs.PathDelimiter)
-		qs.PathDelimiter = ""
+	for i, k := range keys {
+		keys[i] = unescapeLenient(k)
 	}
 
-	for key, val := range pq {
-		keys, err := parseKey(key, qs.MaxDepth)
-		if err != nil {
-			return nil, err
+	if _, err := unescape(p.value); err != nil {
+		valueOffset := p.offset + len(p.key) + 1
+		pErr := &ParseError{Key: p.key, Offset: valueOffset + int(err.(escapeError)), Reason: ReasonBadEscape}
+		if err := q.fail(pErr, false); err != nil {
+			return err
 		}
+	}
 
-		qs.Set(toISlice(val), keys...)
+	rawVals := []string{p.value}
+	if q.Comma {
+		rawVals = strings.Split(p.value, ",")
 	}
 
-	return qs, nil
+	vals := make([]interface{}, len(rawVals))
+	for i, v := range rawVals {
+		vals[i] = unescapeLenient(v)
+	}
+
+	path := trimPath(keys)
+	if len(path) == 0 {
+		return nil
+	}
+
+	if q.MaxNodes > 0 {
+		added := q.countMissing(path)
+		if q.nodes+added > q.MaxNodes {
+			return q.fail(limitError(p, "MaxNodes"), true)
+		}
+		q.nodes += added
+	}
+
+	if q.MaxValuesPerKey > 0 {
+		if q.values == nil {
+			q.values = make(map[string]int)
+		}
+		key := pathKey(q.arrayPath(path))
+		existing := q.values[key]
+
+		if existing+len(vals) > q.MaxValuesPerKey {
+			keep := q.MaxValuesPerKey - existing
+			if keep < 0 {
+				keep = 0
+			}
+			if err := q.fail(limitError(p, "MaxValuesPerKey"), keep == 0); err != nil {
+				return err
+			}
+			vals = vals[:keep]
+		}
+		q.values[key] += len(vals)
+	}
+
+	n := q.navigate(path...)
+	n.Values = append(n.Values, vals...)
+
+	return nil
+}
+
+// arrayPath returns the path of the node that the values at path end up in
+// once arrays are collapsed. Trailing array indices are removed, since
+// collapseArrays appends the values of indexed subkeys to their parent, but
+// the top level key is always kept.
+func (q *QS) arrayPath(path []string) []string {
+	if q.ArrayLimit < 0 {
+		return path
+	}
+
+	for len(path) > 1 {
+		if _, ok := arrayIndex(path[len(path)-1], q.ArrayLimit); !ok {
+			break
+		}
+		path = path[:len(path)-1]
+	}
+
+	return path
+}
+
+// limitError builds the error returned when a pair exceeds the named limit.
+func limitError(p pair, limit string) *ParseError {
+	return &ParseError{Key: p.key, Offset: p.offset, Reason: ReasonLimitExceeded, Limit: limit}
+}
+
+// countMissing returns the number of nodes that navigate would create for the
+// given path.
+func (q *QS) countMissing(path []string) int {
+	currNode := q.root()
+	for i, p := range path {
+		childNode, ok := currNode.Children[p]
+		if !ok {
+			return len(path) - i
+		}
+		currNode = childNode
+	}
+	return 0
+}
+
+// fail reports a problem found while parsing. Unless Lenient is set, the
+// error is returned as is. In lenient mode, the error is recorded as a warning
+// and nil is returned so that parsing can continue.
+func (q *QS) fail(err *ParseError, dropped bool) error {
+	if !q.Lenient {
+		return err
+	}
+
+	q.warnings = append(q.warnings, Warning{ParseError: err, Dropped: dropped})
+	return nil
 }
 
-func parseKey(key string, maxDepth int) ([]string, error) {
+// Warnings returns the problems that were worked around while parsing in
+// lenient mode. The slice is empty if Lenient is not set or if the query
+// string was well formed.
+func (q *QS) Warnings() []Warning {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return append([]Warning{}, q.warnings...)
+}
+
+// parseKey splits a raw key into its subkeys. If AllowDots is set, then a
+// '.' outside of brackets also starts a new subkey. A *ParseError is returned
+// if the brackets in the key are unbalanced, or if the key is deeper than
+// MaxDepth and StrictDepth is set. The offset of the error is relative to the
+// start of the key. When the depth is exceeded, the truncated subkeys are
+// returned along with the error.
+func (q *QS) parseKey(key string) ([]string, error) {
 	inBrackets := false
+	truncated := false
+	opened := 0
 	cur := make([]rune, 0)
 	keys := make([]string, 0)
 	depth := 0
 
 	for i, c := range key {
-		if c == '[' && !inBrackets {
-			inBrackets = true
+		if (c == '[' || (q.AllowDots && c == '.')) && !inBrackets {
+			inBrackets = c == '['
+			opened = i
 			keys = append(keys, string(cur))
 			cur = cur[:0]
 			depth++
 
-			if depth == maxDepth {
-				cur = []rune(key)[i:]
+			if depth == q.MaxDepth {
+				cur = []rune(key[i:])
+				truncated = true
 				break
 			}
 		} else if c == ']' && inBrackets {
 			inBrackets = false
 		} else if (c == '[' && inBrackets) || (c == ']' && !inBrackets) {
-			return nil, ErrInvalidQS
+			return nil, &ParseError{Key: key, Offset: i, Reason: ReasonUnbalanced}
 		} else {
 			cur = append(cur, c)
 		}
 	}
 
-	keys = append(keys, string(cur))
+	if inBrackets && !truncated {
+		return nil, &ParseError{Key: key, Offset: opened, Reason: ReasonUnbalanced}
+	}
 
-	return keys, nil
-}
+	keys = append(keys, string(cur))
 
-func toISlice(s []string) []interface{} {
-	iSlice := make([]interface{}, len(s))
-	for i, e := range s {
-		iSlice[i] = interface{}(e)
+	if truncated && q.StrictDepth {
+		return keys, &ParseError{Key: key, Offset: opened, Reason: ReasonDepthExceeded}
 	}
-	return iSlice
+
+	return keys, nil
 }
 
 func newNode(key string) *node {
@@ -154,25 +604,100 @@ func newNode(key string) *node {
 		Key:      key,
 		Values:   make([]interface{}, 0),
 		Children: make(map[string]*node),
+		Order:    make([]string, 0),
 	}
 }
 
-func (q *QS) navigate(path ...string) *node {
-	pLen := len(path)
+// child returns the child node with the given key, creating it if it does
+// not exist.
+func (n *node) child(key string) *node {
+	c, ok := n.Children[key]
+	if !ok {
+		c = newNode(key)
+		n.Children[key] = c
+		n.Order = append(n.Order, key)
+	}
+	return c
+}
 
-	if path[pLen-1] == "" {
-		path = path[0 : pLen-1]
-		pLen--
+// removeChild deletes the child node with the given key.
+func (n *node) removeChild(key string) {
+	if _, ok := n.Children[key]; !ok {
+		return
 	}
+	delete(n.Children, key)
 
-	currNode := q.Values
-	for i, p := range path {
+	for i, k := range n.Order {
+		if k == key {
+			n.Order = append(n.Order[:i:i], n.Order[i+1:]...)
+			break
+		}
+	}
+}
+
+// keys returns the keys of the node's children in insertion order. Any
+// children missing from Order are appended in sorted order.
+func (n *node) keys() []string {
+	keys := make([]string, 0, len(n.Children))
+	seen := make(map[string]bool, len(n.Children))
+	for _, k := range n.Order {
+		if _, ok := n.Children[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == len(n.Children) {
+		return keys
+	}
+
+	rest := make([]string, 0, len(n.Children)-len(keys))
+	for k := range n.Children {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// trimPath removes a trailing empty subkey from the path e.g. the path of
+// a[b][] is treated as the path of a[b].
+func trimPath(path []string) []string {
+	if len(path) > 0 && path[len(path)-1] == "" {
+		return path[:len(path)-1]
+	}
+	return path
+}
+
+// find follows the provided path and returns the node at the end. Unlike
+// navigate, no nodes are created. If the path does not exist, nil is
+// returned.
+func (q *QS) find(path ...string) *node {
+	if len(path) == 0 {
+		return nil
+	}
+
+	currNode := q.root()
+	for _, p := range path {
 		childNode, ok := currNode.Children[p]
 		if !ok {
-			currNode.Children[p] = newNode(p)
-			childNode = currNode.Children[p]
+			return nil
 		}
 		currNode = childNode
+	}
+
+	return currNode
+}
+
+func (q *QS) navigate(path ...string) *node {
+	path = trimPath(path)
+	pLen := len(path)
+
+	currNode := q.root()
+	for i, p := range path {
+		currNode = currNode.child(p)
 
 		if i+1 == pLen {
 			return currNode
@@ -185,8 +710,8 @@ func (q *QS) navigate(path ...string) *node {
 // Set follows the provided path and overwrites the values
 // at the end with the provided values.
 func (q *QS) Set(vals []interface{}, path ...string) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
+	q.lock()
+	defer q.unlock()
 
 	if q.PathDelimiter != "" && len(path) > 0 {
 		path = strings.Split(path[0], q.PathDelimiter)
@@ -201,8 +726,8 @@ func (q *QS) Set(vals []interface{}, path ...string) {
 // Add follows the provided path and appends the given value
 // to the list of values at the end.
 func (q *QS) Add(val interface{}, path ...string) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
+	q.lock()
+	defer q.unlock()
 
 	if q.PathDelimiter != "" && len(path) > 0 {
 		path = strings.Split(path[0], q.PathDelimiter)
@@ -214,6 +739,87 @@ func (q *QS) Add(val interface{}, path ...string) {
 	}
 }
 
+// Delete follows the provided path and removes the node at the end along
+// with all of its subkeys. Any ancestors left without values or subkeys are
+// removed as well. Deleting a path that does not exist does nothing.
+func (q *QS) Delete(path ...string) {
+	q.lock()
+	defer q.unlock()
+
+	q.delete(trimPath(q.splitPath(path)))
+}
+
+// delete removes the node at the end of path and prunes empty ancestors. The
+// caller must hold the write lock.
+func (q *QS) delete(path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	parents := make([]*node, 0, len(path))
+	currNode := q.root()
+	for _, p := range path {
+		childNode, ok := currNode.Children[p]
+		if !ok {
+			return
+		}
+		parents = append(parents, currNode)
+		currNode = childNode
+	}
+
+	parents[len(parents)-1].removeChild(path[len(path)-1])
+	q.prune(parents, path)
+}
+
+// prune removes empty nodes from the end of a chain of parents, stopping at
+// the first node that has values or subkeys. The root node is never removed.
+// The parents slice holds the nodes along path, starting from the root.
+func (q *QS) prune(parents []*node, path []string) {
+	for i := len(parents) - 1; i > 0; i-- {
+		n := parents[i]
+		if len(n.Values) > 0 || len(n.Children) > 0 {
+			return
+		}
+		parents[i-1].removeChild(path[i-1])
+	}
+}
+
+// Has reports whether a node exists at the provided path.
+func (q *QS) Has(path ...string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.find(q.splitPath(path)...) != nil
+}
+
+// Keys returns the subkeys of the node at the provided path in the order they
+// were first parsed or added. If no path is provided, the top level keys are
+// returned. If the path does not exist, nil is returned.
+func (q *QS) Keys(path ...string) []string {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := q.findOrRoot(path)
+	if n == nil {
+		return nil
+	}
+
+	return n.keys()
+}
+
+// splitPath splits the first path element on the PathDelimiter if one is
+// set. Otherwise, the path is returned as is.
+func (q *QS) splitPath(path []string) []string {
+	if q.PathDelimiter != "" && len(path) > 0 {
+		return strings.Split(path[0], q.PathDelimiter)
+	}
+	return path
+}
+
 // Get follows the provided keys and returns the value at the end.
 // If there are multiple values at the provided path, only the first
 // is returned. Use GetAll to retrieve all values. This function does not
@@ -226,7 +832,7 @@ func (q *QS) Get(path ...string) interface{} {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	currNode := q.Values
+	currNode := q.root()
 	var ok bool
 
 	if q.PathDelimiter != "" {
@@ -251,50 +857,64 @@ func (q *QS) Get(path ...string) interface{} {
 	return nil
 }
 
-// GetString retrieves the value at the given path as a string.
+// GetString retrieves the value at the given path as a string. Values that
+// are not strings are formatted the same way as by String.
 func (q *QS) GetString(path ...string) string {
-	return cast.ToString(q.Get(path...))
+	return q.converter().toString(q.Get(path...))
 }
 
 // GetStringSlice retrieves all values at a given path as a string slice.
+// Values that are not strings are formatted the same way as by String.
 func (q *QS) GetStringSlice(path ...string) []string {
-	return cast.ToStringSlice(q.GetAll(path...))
+	return q.converter().toStrings(q.GetAll(path...))
 }
 
 // GetInt retrieves the value at the given path as an int. If
 // the value cannot be converted to an int, 0 is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetInt(path ...string) int {
-	return cast.ToInt(q.Get(path...))
+	v, _ := q.GetIntE(path...)
+	return v
 }
 
 // GetInt32 retrieves the value at the given path as an int32. If
 // the value cannot be converted to an int, 0 is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetInt32(path ...string) int32 {
-	return cast.ToInt32(q.Get(path...))
+	v, _ := q.GetInt32E(path...)
+	return v
 }
 
 // GetInt64 retrieves the value at the given path as an int64. If
 // the value cannot be converted to an int, 0 is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetInt64(path ...string) int64 {
-	return cast.ToInt64(q.Get(path...))
+	v, _ := q.GetInt64E(path...)
+	return v
 }
 
 // GetFloat32 retrieves the value at the given path as a float32. If
 // the value cannot be converted to a float, 0 is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetFloat32(path ...string) float32 {
-	return cast.ToFloat32(q.Get(path...))
+	v, _ := q.GetFloat32E(path...)
+	return v
 }
 
 // GetFloat64 retrieves the value at the given path as a float64. If
 // the value cannot be converted to a float, 0 is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetFloat64(path ...string) float64 {
-	return cast.ToFloat64(q.Get(path...))
+	v, _ := q.GetFloat64E(path...)
+	return v
 }
 
 // GetBool retrieves the value at the given path as a bool. If
-// the value cannot be converted to a float, false is returned.
+// the value cannot be converted to a bool, false is returned.
+// The conversion rules depend on the Conversion option.
 func (q *QS) GetBool(path ...string) bool {
-	return cast.ToBool(q.Get(path...))
+	v, _ := q.GetBoolE(path...)
+	return v
 }
 
 // GetWithDefault follows the provided keys and returns the value at the end.
@@ -320,7 +940,7 @@ func (q *QS) GetAll(path ...string) []interface{} {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	currNode := q.Values
+	currNode := q.root()
 	var ok bool
 
 	if q.PathDelimiter != "" {
@@ -360,41 +980,149 @@ func (q *QS) String() string {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	return print("", q.Values, false)
+	return q.printer(false).print(q.root())
 }
 
 // EncodedString converts a QS data structure into its string form. All keys and
-// values are encoded and ready for use in a URL.
+// values are encoded and ready for use in a URL. The brackets separating
+// subkeys are left unencoded so that they are parsed as subkeys by New, while
+// any brackets within a key are encoded.
 func (q *QS) EncodedString() string {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	return print("", q.Values, true)
+	return q.printer(true).print(q.root())
 }
 
-func print(key string, n *node, encode bool) string {
-	if key == "" {
-		key = n.Key
-	} else {
-		key = fmt.Sprintf("%s[%s]", key, n.Key)
+// printer holds the settings used to convert a tree into a query string.
+type printer struct {
+	encode bool
+	less   func(a, b string) bool
+	format ArrayStyle
+	dots   bool
+	prefix []string
+	conv   converter
+}
+
+func (q *QS) printer(encode bool) printer {
+	p := printer{
+		encode: encode,
+		less:   q.Sort,
+		format: q.ArrayFormat,
+		dots:   q.DotNotation,
+		conv:   q.converter(),
+	}
+
+	if q.PrefixKeys {
+		p.prefix = q.prefix
 	}
 
+	return p
+}
+
+func (p printer) print(root *node) string {
 	s := make([]string, 0)
-	for _, val := range n.Values {
-		if encode {
-			s = append(s, fmt.Sprintf("%s=%v", url.QueryEscape(key), url.QueryEscape(fmt.Sprintf("%v", val))))
-		} else {
-			s = append(s, fmt.Sprintf("%s=%v", key, val))
+
+	key := ""
+	if len(p.prefix) > 0 {
+		for _, segment := range p.prefix {
+			key = p.joinKey(key, segment)
 		}
+		s = p.appendValues(s, key, root)
 	}
 
-	if len(n.Children) == 0 {
-		return strings.Join(s, "&")
+	for _, k := range p.keys(root) {
+		s = p.appendNode(s, key, root.Children[k])
 	}
 
-	for _, v := range n.Children {
-		s = append(s, print(key, v, encode))
+	return strings.Join(s, "&")
+}
+
+func (p printer) appendNode(s []string, key string, n *node) []string {
+	key = p.joinKey(key, n.Key)
+
+	s = p.appendValues(s, key, n)
+
+	for _, k := range p.keys(n) {
+		s = p.appendNode(s, key, n.Children[k])
 	}
 
-	return strings.Join(s, "&")
+	return s
+}
+
+// joinKey appends a subkey to a key that has already been written.
+func (p printer) joinKey(key, segment string) string {
+	if p.encode {
+		segment = url.QueryEscape(segment)
+		if p.dots {
+			segment = strings.ReplaceAll(segment, ".", "%2E")
+		}
+	}
+
+	if key == "" {
+		return segment
+	} else if p.dots {
+		return key + "." + segment
+	}
+	return fmt.Sprintf("%s[%s]", key, segment)
+}
+
+// appendValues writes the values of n under key. A key with more than one
+// value is written using the printer's array format. ArrayIndices falls back
+// to ArrayRepeat if n has indexed subkeys, since their indices would clash
+// with the ones given to the values.
+func (p printer) appendValues(s []string, key string, n *node) []string {
+	vals := n.Values
+	if len(vals) > 1 {
+		switch p.format {
+		case ArrayBrackets:
+			key += "[]"
+		case ArrayIndices:
+			if hasIndexedChild(n) {
+				break
+			}
+			for i, val := range vals {
+				s = append(s, fmt.Sprintf("%s[%d]=%s", key, i, p.value(val)))
+			}
+			return s
+		case ArrayComma:
+			parts := make([]string, len(vals))
+			for i, val := range vals {
+				parts[i] = p.value(val)
+			}
+			return append(s, key+"="+strings.Join(parts, ","))
+		}
+	}
+
+	for _, val := range vals {
+		s = append(s, key+"="+p.value(val))
+	}
+
+	return s
+}
+
+// hasIndexedChild reports whether any subkey of n is an array index.
+func hasIndexedChild(n *node) bool {
+	for k := range n.Children {
+		if _, ok := arrayIndex(k, math.MaxInt); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p printer) value(val interface{}) string {
+	if p.encode {
+		return url.QueryEscape(p.conv.format(val))
+	}
+	return p.conv.format(val)
+}
+
+// keys returns the keys of n's children in the order they should be printed.
+func (p printer) keys(n *node) []string {
+	keys := n.keys()
+	if p.less != nil {
+		sort.SliceStable(keys, func(i, j int) bool { return p.less(keys[i], keys[j]) })
+	}
+	return keys
 }
@@ -36,29 +36,37 @@ func TestNewQS(t *testing.T) {
 										Key:      "d",
 										Values:   []interface{}{"c"},
 										Children: make(map[string]*node),
+										Order:    make([]string, 0),
 									},
 								},
+								Order: []string{"d"},
 							},
 						},
+						Order: []string{"c"},
 					},
 					"g": {
 						Key:      "g",
 						Values:   []interface{}{"h", "i"},
 						Children: make(map[string]*node),
+						Order:    make([]string, 0),
 					},
 				},
+				Order: []string{"b", "g"},
 			},
 			"d": {
 				Key:      "d",
 				Values:   []interface{}{"1.05", "2.5"},
 				Children: make(map[string]*node),
+				Order:    make([]string, 0),
 			},
 			"j": {
 				Key:      "j",
 				Values:   []interface{}{"true"},
 				Children: make(map[string]*node),
+				Order:    make([]string, 0),
 			},
 		},
+		Order: []string{"a", "d", "j"},
 	}
 
 	if !(cmp.Equal(q.Values, exp)) {
@@ -581,10 +589,141 @@ func TestQS_Add(t *testing.T) {
 	}
 }
 
+func TestQS_Delete(t *testing.T) {
+	query := "a[b]=123&a[b][c][d]=c&a[g]=h&a[g]=i&d[]=1.05&j=true"
+	tests := []struct {
+		name      string
+		delimiter string
+		path      []string
+		want      string
+	}{
+		{
+			name: "Delete nothing",
+			path: []string{},
+			want: "a[b]=123&a[b][c][d]=c&a[g]=h&a[g]=i&d=1.05&j=true",
+		},
+		{
+			name: "Delete missing",
+			path: []string{"a", "z"},
+			want: "a[b]=123&a[b][c][d]=c&a[g]=h&a[g]=i&d=1.05&j=true",
+		},
+		{
+			name: "Delete leaf",
+			path: []string{"j"},
+			want: "a[b]=123&a[b][c][d]=c&a[g]=h&a[g]=i&d=1.05",
+		},
+		{
+			name: "Delete subtree",
+			path: []string{"a", "b"},
+			want: "a[g]=h&a[g]=i&d=1.05&j=true",
+		},
+		{
+			name: "Prune empty ancestors",
+			path: []string{"a", "b", "c", "d"},
+			want: "a[b]=123&a[g]=h&a[g]=i&d=1.05&j=true",
+		},
+		{
+			name:      "Delete w/ delimiter",
+			delimiter: ".",
+			path:      []string{"a.g"},
+			want:      "a[b]=123&a[b][c][d]=c&d=1.05&j=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(query, PathDelimiter(tt.delimiter))
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			q.Delete(tt.path...)
+			if got := q.String(); got != tt.want {
+				t.Errorf("QS.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_Delete_PrunesToRoot(t *testing.T) {
+	q, err := New("a[b][c]=1")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	q.Delete("a", "b", "c")
+	if q.Has("a") {
+		t.Errorf("QS.Has(a) = true after deleting its only leaf")
+	}
+	if got := q.String(); got != "" {
+		t.Errorf("QS.String() = %v, want empty string", got)
+	}
+}
+
+func TestQS_Has(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		path      []string
+		want      bool
+	}{
+		{name: "No path", path: []string{}, want: false},
+		{name: "Leaf", path: []string{"a", "g"}, want: true},
+		{name: "Branch", path: []string{"a", "b", "c"}, want: true},
+		{name: "Missing", path: []string{"a", "z"}, want: false},
+		{name: "Delimiter", delimiter: ".", path: []string{"a.b.c.d"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(
+				"a[b]=123&a[b][c][d]=c&a[g]=h&a[g]=i&d[]=1.05&j=true",
+				PathDelimiter(tt.delimiter),
+			)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.Has(tt.path...); got != tt.want {
+				t.Errorf("QS.Has() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_Keys(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		path      []string
+		want      []string
+	}{
+		{name: "Top level", path: []string{}, want: []string{"j", "a", "d"}},
+		{name: "Nested", path: []string{"a"}, want: []string{"g", "b"}},
+		{name: "Leaf", path: []string{"j"}, want: []string{}},
+		{name: "Missing", path: []string{"z"}, want: nil},
+		{name: "Delimiter", delimiter: ".", path: []string{"a.b"}, want: []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(
+				"j=true&a[g]=h&a[b]=123&a[b][c][d]=c&d[]=1.05",
+				PathDelimiter(tt.delimiter),
+			)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.Keys(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.Keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_parseKey(t *testing.T) {
 	type args struct {
-		key      string
-		maxDepth int
+		key       string
+		maxDepth  int
+		allowDots bool
 	}
 	tests := []struct {
 		name    string
@@ -622,6 +761,12 @@ func Test_parseKey(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "alpha[beta",
+			args:    args{key: "alpha[beta"},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "a[b][c][d] max 2",
 			args:    args{key: "a[b][c][d]", maxDepth: 2},
@@ -640,10 +785,47 @@ func Test_parseKey(t *testing.T) {
 			want:    []string{"a", "b", "c", "d"},
 			wantErr: false,
 		},
+		{
+			name:    "multibyte max 1",
+			args:    args{key: "é[b][c]", maxDepth: 1},
+			want:    []string{"é", "[b][c]"},
+			wantErr: false,
+		},
+		{
+			name:    "a.b without dots",
+			args:    args{key: "a.b"},
+			want:    []string{"a.b"},
+			wantErr: false,
+		},
+		{
+			name:    "a.b.c with dots",
+			args:    args{key: "a.b.c", allowDots: true},
+			want:    []string{"a", "b", "c"},
+			wantErr: false,
+		},
+		{
+			name:    "a.b[c].d with dots",
+			args:    args{key: "a.b[c].d", allowDots: true},
+			want:    []string{"a", "b", "c", "d"},
+			wantErr: false,
+		},
+		{
+			name:    "a[b.c] with dots",
+			args:    args{key: "a[b.c]", allowDots: true},
+			want:    []string{"a", "b.c"},
+			wantErr: false,
+		},
+		{
+			name:    "a.b.c.d with dots max 2",
+			args:    args{key: "a.b.c.d", maxDepth: 2, allowDots: true},
+			want:    []string{"a", "b", ".c.d"},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseKey(tt.args.key, tt.args.maxDepth)
+			q := &QS{MaxDepth: tt.args.maxDepth, AllowDots: tt.args.allowDots}
+			got, err := q.parseKey(tt.args.key)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseKey() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -802,7 +984,7 @@ func TestQS_EncodedString(t *testing.T) {
 					},
 				},
 			},
-			want: "a=val1&b=val2&b=val3&b%5Bc%5D=val4",
+			want: "a=val1&b=val2&b=val3&b[c]=val4",
 		},
 	}
 	for _, tt := range tests {
@@ -822,6 +1004,146 @@ func TestQS_EncodedString(t *testing.T) {
 	}
 }
 
+func TestQS_String_Order(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  []Option
+		set   [][]string
+		want  string
+	}{
+		{
+			name:  "Parsed order",
+			query: "z=1&a[y]=2&b=3&a[x]=4&z=5",
+			want:  "z=1&z=5&a[y]=2&a[x]=4&b=3",
+		},
+		{
+			name:  "Added keys",
+			query: "z=1",
+			set:   [][]string{{"m", "n"}, {"c"}, {"m", "a"}},
+			want:  "z=1&m[n]=v&m[a]=v&c=v",
+		},
+		{
+			name:  "Sorted keys",
+			query: "z=1&a[y]=2&b=3&a[x]=4",
+			opts:  []Option{SortKeys()},
+			want:  "a[x]=4&a[y]=2&b=3&z=1",
+		},
+		{
+			name:  "Custom comparator",
+			query: "aa=1&b=2&ccc=3",
+			opts:  []Option{Sort(func(a, b string) bool { return len(a) > len(b) })},
+			want:  "ccc=3&aa=1&b=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			for _, path := range tt.set {
+				q.Set([]interface{}{"v"}, path...)
+			}
+
+			for i := 0; i < 10; i++ {
+				if got := q.String(); got != tt.want {
+					t.Fatalf("QS.String() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_AllowDots(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  []Option
+		path  []string
+		want  []interface{}
+	}{
+		{
+			name:  "Disabled",
+			query: "filter.status=open",
+			path:  []string{"filter.status"},
+			want:  []interface{}{"open"},
+		},
+		{
+			name:  "Enabled",
+			query: "filter.status=open&filter.owner.id=7",
+			opts:  []Option{AllowDots()},
+			path:  []string{"filter", "owner", "id"},
+			want:  []interface{}{"7"},
+		},
+		{
+			name:  "Mixed with brackets",
+			query: "filter[owner].id=7",
+			opts:  []Option{AllowDots()},
+			path:  []string{"filter", "owner", "id"},
+			want:  []interface{}{"7"},
+		},
+		{
+			name:  "Encoded dot",
+			query: "a%2Eb.c=1",
+			opts:  []Option{AllowDots()},
+			path:  []string{"a.b", "c"},
+			want:  []interface{}{"1"},
+		},
+		{
+			name:  "Max depth",
+			query: "a.b.c=1",
+			opts:  []Option{AllowDots(), MaxDepth(1)},
+			path:  []string{"a", ".b.c"},
+			want:  []interface{}{"1"},
+		},
+		{
+			name:  "Path delimiter is independent",
+			query: "a.b=1",
+			opts:  []Option{AllowDots(), PathDelimiter("/")},
+			path:  []string{"a/b"},
+			want:  []interface{}{"1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			if got := q.GetAll(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_String_DotNotation(t *testing.T) {
+	q, err := New("a[b][c]=1&a[d%2Ee]=2&f=3", DotNotation())
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got, want := q.String(), "a.b.c=1&a.d.e=2&f=3"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+
+	encoded := q.EncodedString()
+	if want := "a.b.c=1&a.d%2Ee=2&f=3"; encoded != want {
+		t.Errorf("QS.EncodedString() = %v, want %v", encoded, want)
+	}
+
+	q2, err := New(encoded, AllowDots())
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	if !reflect.DeepEqual(q.Values, q2.Values) {
+		t.Errorf("round trip through %q changed the tree", encoded)
+	}
+}
+
 func assertQueryStringsEqual(a, b string) bool {
 	aParts := strings.Split(a, "&")
 	bParts := strings.Split(b, "&")
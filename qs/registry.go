@@ -0,0 +1,101 @@
+package qs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var registry sync.Map // map[reflect.Type]Converter
+
+// DecodeFunc converts the string form of a value into a value of the type it
+// was registered for.
+type DecodeFunc func(s string) (interface{}, error)
+
+// EncodeFunc converts a value of the type it was registered for into its
+// string form.
+type EncodeFunc func(v interface{}) (string, error)
+
+// Converter holds the functions used to convert values of a custom type.
+// Either function may be nil, in which case the default rules apply in that
+// direction.
+type Converter struct {
+	Decode DecodeFunc
+	Encode EncodeFunc
+}
+
+// RegisterType registers the functions used to convert values of type t for
+// every QS. Decode is used by the typed and generic getters and by Decode,
+// and encode is used when stringifying values of type t. Converters set with
+// the Converters option take precedence. Registering a type again replaces the
+// previous functions.
+//
+// Types implementing encoding.TextUnmarshaler and encoding.TextMarshaler do not
+// need to be registered.
+//
+//	qs.RegisterType(reflect.TypeOf(OrderID(0)), parseOrderID, formatOrderID)
+func RegisterType(t reflect.Type, decode DecodeFunc, encode EncodeFunc) {
+	registry.Store(t, Converter{Decode: decode, Encode: encode})
+}
+
+// converter applies the options of a QS that affect how values are converted.
+type converter struct {
+	types   map[reflect.Type]Converter
+	layouts []string
+	mode    ConversionMode
+}
+
+func (q *QS) converter() converter {
+	return converter{types: q.Converters, layouts: q.TimeLayouts, mode: q.Conversion}
+}
+
+// lookup returns the converter for t, preferring the converters of the QS
+// over the registered ones.
+func (c converter) lookup(t reflect.Type) (Converter, bool) {
+	if conv, ok := c.types[t]; ok {
+		return conv, true
+	}
+	if conv, ok := registry.Load(t); ok {
+		return conv.(Converter), true
+	}
+	return Converter{}, false
+}
+
+// toString returns the string form of a value as written by String, except
+// that nil becomes the empty string.
+func (c converter) toString(val interface{}) string {
+	if val == nil {
+		return ""
+	}
+	return c.format(val)
+}
+
+// toStrings converts each value with toString.
+func (c converter) toStrings(vals []interface{}) []string {
+	s := make([]string, len(vals))
+	for i, val := range vals {
+		s[i] = c.toString(val)
+	}
+	return s
+}
+
+// format returns the string form of a value. Registered types use their
+// EncodeFunc, falling back to encoding.TextMarshaler and then fmt.
+func (c converter) format(val interface{}) string {
+	if val == nil {
+		return fmt.Sprintf("%v", val)
+	}
+
+	if conv, ok := c.lookup(reflect.TypeOf(val)); ok && conv.Encode != nil {
+		if s, err := conv.Encode(val); err == nil {
+			return s
+		}
+	} else if m, ok := val.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprintf("%v", val)
+}
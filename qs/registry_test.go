@@ -0,0 +1,169 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type orderID int
+
+type money struct {
+	Cents int64
+}
+
+type region string
+
+func (r region) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(string(r))), nil
+}
+
+func (r *region) UnmarshalText(text []byte) error {
+	*r = region(strings.ToUpper(string(text)))
+	return nil
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(orderID(0)), func(s string) (interface{}, error) {
+		if !strings.HasPrefix(s, "ord_") {
+			return nil, errors.New("missing ord_ prefix")
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(s, "ord_"))
+		return orderID(i), err
+	}, func(v interface{}) (string, error) {
+		return fmt.Sprintf("ord_%d", v.(orderID)), nil
+	})
+}
+
+var moneyConverters = map[reflect.Type]Converter{
+	reflect.TypeOf(money{}): {
+		Decode: func(s string) (interface{}, error) {
+			f, err := strconv.ParseFloat(s, 64)
+			return money{Cents: int64(f * 100)}, err
+		},
+		Encode: func(v interface{}) (string, error) {
+			m := v.(money)
+			return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100), nil
+		},
+	},
+}
+
+func TestRegisterType_Getters(t *testing.T) {
+	q, err := New("id=ord_42&bad=42&ids=ord_1&ids=ord_2&price=12.5&region=eu", Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got, err := Get[orderID](q, "id"); err != nil || got != 42 {
+		t.Errorf("Get() = %v, %v, want %v", got, err, orderID(42))
+	}
+	if got, err := Get[*orderID](q, "id"); err != nil || got == nil || *got != 42 {
+		t.Errorf("Get() = %v, %v, want a pointer to %v", got, err, orderID(42))
+	}
+
+	var convErr *ConversionError
+	if _, err := Get[orderID](q, "bad"); !errors.As(err, &convErr) {
+		t.Errorf("Get() error = %v, want a *ConversionError", err)
+	}
+
+	if got, err := GetSlice[orderID](q, "ids"); err != nil || !reflect.DeepEqual(got, []orderID{1, 2}) {
+		t.Errorf("GetSlice() = %v, %v, want %v", got, err, []orderID{1, 2})
+	}
+	if got, err := Get[money](q, "price"); err != nil || got.Cents != 1250 {
+		t.Errorf("Get() = %v, %v, want %v", got, err, money{Cents: 1250})
+	}
+	if got, err := Get[region](q, "region"); err != nil || got != "EU" {
+		t.Errorf("Get() = %v, %v, want %v", got, err, region("EU"))
+	}
+
+	q2, err := New("price=12.5")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+	if _, err := Get[money](q2, "price"); err == nil {
+		t.Errorf("Get() without the converter succeeded")
+	}
+}
+
+func TestRegisterType_Decode(t *testing.T) {
+	type order struct {
+		ID     orderID   `qs:"id"`
+		Price  *money    `qs:"price"`
+		Region region    `qs:"region"`
+		Refs   []orderID `qs:"refs"`
+	}
+
+	var got order
+	err := Unmarshal("id=ord_7&price=3.05&region=us&refs=ord_1&refs=ord_2", &got, Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("Unmarshal failed with err, %s", err)
+	}
+
+	want := order{ID: 7, Price: &money{Cents: 305}, Region: "US", Refs: []orderID{1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegisterType_String(t *testing.T) {
+	q, err := New("", Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	q.Set([]interface{}{orderID(9)}, "id")
+	q.Set([]interface{}{money{Cents: 1999}}, "price")
+	q.Add(region("EU"), "region")
+
+	if got, want := q.String(), "id=ord_9&price=19.99&region=eu"; got != want {
+		t.Errorf("QS.String() = %v, want %v", got, want)
+	}
+
+	type order struct {
+		ID    orderID `qs:"id"`
+		Price money   `qs:"price"`
+	}
+	s, err := MarshalString(order{ID: 3, Price: money{Cents: 100}}, Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("MarshalString failed with err, %s", err)
+	}
+	if want := "id=ord_3&price=1.00"; s != want {
+		t.Errorf("MarshalString() = %v, want %v", s, want)
+	}
+}
+
+func TestRegisterType_GetString(t *testing.T) {
+	q, err := New("", Converters(moneyConverters))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	ts := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	q.Set([]interface{}{orderID(9)}, "id")
+	q.Set([]interface{}{money{Cents: 250}, ts}, "m", "a")
+
+	if got, err := q.GetStringE("id"); err != nil || got != "ord_9" {
+		t.Errorf("QS.GetStringE() = %v, %v, want %v", got, err, "ord_9")
+	}
+	if got := q.GetString("id"); got != "ord_9" {
+		t.Errorf("QS.GetString() = %v, want %v", got, "ord_9")
+	}
+
+	want := []string{"2.50", "2024-03-01T10:00:00Z"}
+	if got := q.GetStringSlice("m", "a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.GetStringSlice() = %v, want %v", got, want)
+	}
+	if got := q.GetStringMap("m"); got["a"] != want[0] {
+		t.Errorf("QS.GetStringMap() = %v, want a=%v", got, want[0])
+	}
+	if got := q.GetStringMapSlice("m"); !reflect.DeepEqual(got["a"], want) {
+		t.Errorf("QS.GetStringMapSlice() = %v, want a=%v", got, want)
+	}
+	if got, wantString := q.String(), "id=ord_9&m[a]=2.50&m[a]=2024-03-01T10:00:00Z"; got != wantString {
+		t.Errorf("QS.String() = %v, want %v", got, wantString)
+	}
+}
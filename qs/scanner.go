@@ -0,0 +1,115 @@
+package qs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pair is a single key/value pair read from a raw query string. Both the key
+// and the value are still escaped.
+type pair struct {
+	key   string
+	value string
+	// offset is the byte offset of the start of the pair in the raw query
+	// string.
+	offset int
+}
+
+// scan splits a raw query string into its key/value pairs in source order.
+// Pairs are separated by '&' and a key is separated from its value by the
+// first '='. A pair without an '=' has an empty value. Empty pairs are
+// skipped. If limit is greater than zero, scanning stops after limit+1 pairs
+// so that callers can detect that the limit was exceeded without reading the
+// rest of the query string.
+func scan(rawQuery string, limit int) []pair {
+	pairs := make([]pair, 0)
+
+	start := 0
+	for start <= len(rawQuery) && (limit <= 0 || len(pairs) <= limit) {
+		end := strings.IndexByte(rawQuery[start:], '&')
+		if end == -1 {
+			end = len(rawQuery)
+		} else {
+			end += start
+		}
+
+		if end > start {
+			p := pair{key: rawQuery[start:end], offset: start}
+			if i := strings.IndexByte(p.key, '='); i != -1 {
+				p.key, p.value = p.key[:i], p.key[i+1:]
+			}
+			pairs = append(pairs, p)
+		}
+
+		start = end + 1
+	}
+
+	return pairs
+}
+
+// escapeError reports the byte offset of an invalid escape sequence within
+// a query string component.
+type escapeError int
+
+func (e escapeError) Error() string {
+	return "invalid escape sequence at offset " + strconv.Itoa(int(e))
+}
+
+// unescape decodes a single query string component. Percent encoded bytes
+// are decoded and '+' is decoded as a space. An escapeError is returned if a
+// '%' is not followed by two hexadecimal digits.
+func unescape(s string) (string, error) {
+	return decodeComponent(s, false)
+}
+
+// unescapeLenient is like unescape, but invalid escape sequences are kept as
+// literal text rather than causing an error.
+func unescapeLenient(s string) string {
+	d, _ := decodeComponent(s, true)
+	return d
+}
+
+func decodeComponent(s string, lenient bool) (string, error) {
+	if strings.IndexByte(s, '%') == -1 && strings.IndexByte(s, '+') == -1 {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				if lenient {
+					b.WriteByte(c)
+					continue
+				}
+				return "", escapeError(i)
+			}
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case '+':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,164 @@
+package qs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		limit    int
+		want     []pair
+	}{
+		{
+			name:     "empty",
+			rawQuery: "",
+			want:     []pair{},
+		},
+		{
+			name:     "single pair",
+			rawQuery: "a=1",
+			want:     []pair{{key: "a", value: "1", offset: 0}},
+		},
+		{
+			name:     "source order",
+			rawQuery: "b=1&a=2&b=3",
+			want: []pair{
+				{key: "b", value: "1", offset: 0},
+				{key: "a", value: "2", offset: 4},
+				{key: "b", value: "3", offset: 8},
+			},
+		},
+		{
+			name:     "empty pairs and missing values",
+			rawQuery: "&a&&b=&c==d&",
+			want: []pair{
+				{key: "a", value: "", offset: 1},
+				{key: "b", value: "", offset: 4},
+				{key: "c", value: "=d", offset: 7},
+			},
+		},
+		{
+			name:     "limit",
+			rawQuery: "a=1&b=2&c=3&d=4",
+			limit:    2,
+			want: []pair{
+				{key: "a", value: "1", offset: 0},
+				{key: "b", value: "2", offset: 4},
+				{key: "c", value: "3", offset: 8},
+			},
+		},
+		{
+			name:     "escapes are kept",
+			rawQuery: "a%5Bb%5D=x+y",
+			want:     []pair{{key: "a%5Bb%5D", value: "x+y", offset: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scan(tt.rawQuery, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_unescape(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr error
+	}{
+		{name: "plain", s: "abc", want: "abc"},
+		{name: "plus", s: "a+b", want: "a b"},
+		{name: "percent", s: "a%20b%5B%5d", want: "a b[]"},
+		{name: "utf-8", s: "%E2%9C%93", want: "✓"},
+		{name: "truncated", s: "ab%2", wantErr: escapeError(2)},
+		{name: "not hex", s: "%zz", wantErr: escapeError(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unescape(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unescape() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unescape() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Tokenizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		path    []string
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:  "encoded brackets are literal",
+			query: "a%5Bb%5D=1&a[b]=2",
+			path:  []string{"a[b]"},
+			want:  []interface{}{"1"},
+		},
+		{
+			name:  "brackets are structural",
+			query: "a%5Bb%5D=1&a[b]=2",
+			path:  []string{"a", "b"},
+			want:  []interface{}{"2"},
+		},
+		{
+			name:  "encoded subkey",
+			query: "a[b%20c]=1",
+			path:  []string{"a", "b c"},
+			want:  []interface{}{"1"},
+		},
+		{
+			name:  "values in source order",
+			query: "a=1&b=2&a=3",
+			path:  []string{"a"},
+			want:  []interface{}{"1", "3"},
+		},
+		{
+			name:    "bad escape",
+			query:   "a=1&b=%zz",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQS) {
+					t.Errorf("New() error = %v, want %v", err, ErrInvalidQS)
+				}
+				return
+			}
+
+			if got := q.GetAll(tt.path...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQS_EncodedString_RoundTrip(t *testing.T) {
+	query := "a%5Bb%5D=1&a[b]=x%26y&c[d+e]=%2B"
+	q, err := New(query)
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	if got := q.EncodedString(); got != query {
+		t.Errorf("QS.EncodedString() = %v, want %v", got, query)
+	}
+}
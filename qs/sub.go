@@ -0,0 +1,108 @@
+package qs
+
+import "sync"
+
+// Sub returns a view of the subtree at the provided path. The returned QS has
+// the same options as q, and its top level is the node at the path, so that
+// Get("status") on the view of "filter" reads filter[status]. The view shares
+// its tree and its lock with q, so changes made through either are visible to
+// both. The node is only added to the tree of q once something is written
+// through the view, and is removed again if a write leaves it without values
+// or subkeys. The Values field of the view is not used.
+//
+// When stringified, the view writes its keys relative to its own root, and any
+// values held by the root itself are left out. If PrefixKeys is set, keys are
+// instead written with the full path from the top level of q.
+func (q *QS) Sub(path ...string) *QS {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	path = trimPath(q.splitPath(path))
+
+	v := q.view(nil, path, q.mutex)
+	v.origin = q
+	if q.origin != nil {
+		v.origin = q.origin
+	}
+	v.subPath = append(append([]string{}, q.subPath...), path...)
+
+	return v
+}
+
+// Extract returns a detached copy of the subtree at the provided path. The
+// returned QS has the same options as q, but its own tree and lock, so changes
+// made to it do not affect q. Keys are written as described by Sub. If the
+// path does not exist, the returned QS is empty.
+func (q *QS) Extract(path ...string) *QS {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	path = trimPath(q.splitPath(path))
+
+	n := q.root()
+	if len(path) > 0 {
+		n = q.find(path...)
+	}
+
+	if n == nil {
+		n = newNode(path[len(path)-1])
+	} else {
+		n = n.clone()
+	}
+
+	return q.view(n, path, &sync.RWMutex{})
+}
+
+// view returns a QS with the options of q rooted at n. The caller must hold
+// the lock of q.
+func (q *QS) view(n *node, path []string, mutex *sync.RWMutex) *QS {
+	c := *q
+	c.RawQuery = ""
+	c.Values = n
+	c.mutex = mutex
+	c.warnings = nil
+	c.nodes = 0
+	c.values = nil
+	c.prefix = append(append([]string{}, q.prefix...), path...)
+	c.origin = nil
+	c.subPath = nil
+
+	return &c
+}
+
+// root returns the top level node of q. For a view returned by Sub, this is
+// the node at the view's path in the tree of its origin, or an empty node if
+// there is none. The caller must hold the lock.
+func (q *QS) root() *node {
+	if q.origin == nil {
+		return q.Values
+	}
+	if len(q.subPath) == 0 {
+		return q.origin.Values
+	}
+	if n := q.origin.find(q.subPath...); n != nil {
+		return n
+	}
+	return newNode(q.subPath[len(q.subPath)-1])
+}
+
+// lock takes the write lock. For a view, its root is added to the tree of the
+// origin so that writes through the view are kept.
+func (q *QS) lock() {
+	q.mutex.Lock()
+	if q.origin != nil {
+		q.origin.navigate(q.subPath...)
+	}
+}
+
+// unlock releases the write lock taken by lock. For a view, its root is first
+// removed from the tree of the origin, along with any empty ancestors, if it
+// was left without values or subkeys.
+func (q *QS) unlock() {
+	if q.origin != nil {
+		if n := q.origin.find(q.subPath...); n != nil && len(n.Values) == 0 && len(n.Children) == 0 {
+			q.origin.delete(q.subPath)
+		}
+	}
+	q.mutex.Unlock()
+}
@@ -0,0 +1,166 @@
+package qs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQS_Sub(t *testing.T) {
+	q, err := New("filter[status]=open&filter[owner]=me&page=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	s := q.Sub("filter")
+	if s.mutex != q.mutex {
+		t.Errorf("QS.Sub() does not share the lock with the original")
+	}
+	if got := s.GetString("status"); got != "open" {
+		t.Errorf("QS.GetString() = %v, want %v", got, "open")
+	}
+
+	s.Set([]interface{}{"bug"}, "label")
+	q.Delete("filter", "owner")
+
+	if got, want := s.String(), "status=open&label=bug"; got != want {
+		t.Errorf("sub QS.String() = %v, want %v", got, want)
+	}
+	if got, want := q.String(), "filter[status]=open&filter[label]=bug&page=2"; got != want {
+		t.Errorf("original QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Sub_Missing(t *testing.T) {
+	q, err := New("page=2", PathDelimiter("."))
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	s := q.Sub("filter.tags")
+	if q.Has("filter") {
+		t.Errorf("QS.Has() = true after QS.Sub() of a missing path")
+	}
+	if got, want := q.Keys(), []string{"page"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.Keys() = %v, want %v", got, want)
+	}
+	if got := s.String(); got != "" {
+		t.Errorf("sub QS.String() = %v, want empty", got)
+	}
+
+	s.Delete("0")
+	if q.Has("filter") {
+		t.Errorf("QS.Has() = true after QS.Delete() through a view of a missing path")
+	}
+
+	s.Add("a", "0")
+
+	if got, want := q.String(), "page=2&filter[tags][0]=a"; got != want {
+		t.Errorf("original QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Sub_PruneRoot(t *testing.T) {
+	q, err := New("f[s]=1&g=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	s := q.Sub("f")
+	s.Delete("s")
+
+	if q.Has("f") {
+		t.Errorf("QS.Has() = true after deleting the last subkey through a view")
+	}
+	if got, want := q.Keys(), []string{"g"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QS.Keys() = %v, want %v", got, want)
+	}
+
+	s.Set([]interface{}{"2"}, "t")
+	if got, want := q.String(), "g=2&f[t]=2"; got != want {
+		t.Errorf("original QS.String() = %v, want %v", got, want)
+	}
+}
+
+func TestQS_Extract(t *testing.T) {
+	q, err := New("filter[status]=open&page=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	e := q.Extract("filter")
+	if e.mutex == q.mutex {
+		t.Errorf("QS.Extract() shares the lock with the original")
+	}
+
+	e.Set([]interface{}{"closed"}, "status")
+	if got, want := q.String(), "filter[status]=open&page=2"; got != want {
+		t.Errorf("original QS.String() = %v, want %v", got, want)
+	}
+	if got, want := e.String(), "status=closed"; got != want {
+		t.Errorf("extracted QS.String() = %v, want %v", got, want)
+	}
+
+	if got := q.Extract("missing").String(); got != "" {
+		t.Errorf("QS.Extract() of a missing path String() = %v, want empty", got)
+	}
+}
+
+func TestQS_Sub_PrefixKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		path    []string
+		want    string
+		encoded string
+	}{
+		{
+			name:    "Relative",
+			path:    []string{"a", "b c"},
+			want:    "d=1&d=2&e[f]=3",
+			encoded: "d=1&d=2&e[f]=3",
+		},
+		{
+			name:    "Prefixed",
+			opts:    []Option{PrefixKeys()},
+			path:    []string{"a", "b c"},
+			want:    "a[b c]=0&a[b c][d]=1&a[b c][d]=2&a[b c][e][f]=3",
+			encoded: "a[b+c]=0&a[b+c][d]=1&a[b+c][d]=2&a[b+c][e][f]=3",
+		},
+		{
+			name:    "Prefixed with dots",
+			opts:    []Option{PrefixKeys(), DotNotation()},
+			path:    []string{"a", "b c"},
+			want:    "a.b c=0&a.b c.d=1&a.b c.d=2&a.b c.e.f=3",
+			encoded: "a.b+c=0&a.b+c.d=1&a.b+c.d=2&a.b+c.e.f=3",
+		},
+		{
+			name:    "Top level",
+			opts:    []Option{PrefixKeys()},
+			want:    "a[b c]=0&a[b c][d]=1&a[b c][d]=2&a[b c][e][f]=3&g=4",
+			encoded: "a[b+c]=0&a[b+c][d]=1&a[b+c][d]=2&a[b+c][e][f]=3&g=4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New("a[b+c]=0&a[b+c][d]=1&a[b+c][d]=2&a[b+c][e][f]=3&g=4", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			// A nested view keeps the full path.
+			s := q.Sub(tt.path...)
+			if len(tt.path) > 0 {
+				s = q.Sub(tt.path[0]).Sub(tt.path[1:]...)
+			}
+
+			for _, v := range []*QS{s, q.Extract(tt.path...)} {
+				if got := v.String(); got != tt.want {
+					t.Errorf("QS.String() = %v, want %v", got, tt.want)
+				}
+				if got := v.EncodedString(); got != tt.encoded {
+					t.Errorf("QS.EncodedString() = %v, want %v", got, tt.encoded)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package qs
+
+import "errors"
+
+var (
+	// SkipNode can be returned by a WalkFunc to skip the subkeys of the
+	// current node. Walk continues with the next sibling.
+	SkipNode = errors.New("skip this node")
+	// SkipAll can be returned by a WalkFunc to stop the walk. Walk returns
+	// nil in this case.
+	SkipAll = errors.New("skip everything")
+)
+
+// WalkFunc is the type of function called by Walk for every node. The path
+// and values are copies and may be retained by the function. If the function
+// returns SkipNode, the subkeys of the node are skipped. If it returns
+// SkipAll, the walk stops. Any other error stops the walk and is returned by
+// Walk.
+type WalkFunc func(path []string, values []interface{}) error
+
+// Walk calls fn for every node in the tree, depth first, visiting the
+// subkeys of each node in the order they were first parsed or added. The
+// top level placeholder node is not visited. Nodes that only have subkeys are
+// visited with an empty slice of values.
+//
+// The tree is read locked for the duration of the walk, so fn must not use
+// the QS, not even to read from it. Use Iter to work on a snapshot instead.
+func (q *QS) Walk(fn WalkFunc) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	err := walk(q.root(), nil, fn)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walk(n *node, path []string, fn WalkFunc) error {
+	for _, k := range n.keys() {
+		child := n.Children[k]
+		childPath := appendPath(path, k)
+
+		err := fn(childPath, append([]interface{}{}, child.Values...))
+		if err == SkipNode {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := walk(child, childPath, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// leaf is a node that holds values along with its path.
+type leaf struct {
+	path   []string
+	values []interface{}
+}
+
+// leaves returns a snapshot of every node that holds values in walk order.
+func (q *QS) leaves() []leaf {
+	leaves := make([]leaf, 0)
+	_ = q.Walk(func(path []string, values []interface{}) error {
+		if len(values) > 0 {
+			leaves = append(leaves, leaf{path: path, values: values})
+		}
+		return nil
+	})
+	return leaves
+}
+
+// LeafIterator iterates over the nodes of a QS that hold values. Call Next
+// to advance the iterator, then Path and Values to read the current node.
+//
+//	it := q.Iter()
+//	for it.Next() {
+//		fmt.Println(it.Path(), it.Values())
+//	}
+type LeafIterator struct {
+	leaves []leaf
+	i      int
+}
+
+// Iter returns an iterator over every node that holds values, in the same
+// order as Walk. The iterator works on a snapshot taken when Iter is called,
+// so the QS may be modified while iterating.
+func (q *QS) Iter() *LeafIterator {
+	return &LeafIterator{leaves: q.leaves(), i: -1}
+}
+
+// Next advances the iterator and reports whether there is a current node.
+func (it *LeafIterator) Next() bool {
+	if it.i < len(it.leaves) {
+		it.i++
+	}
+	return it.i < len(it.leaves)
+}
+
+// Path returns the path of the current node.
+func (it *LeafIterator) Path() []string {
+	if it.i < 0 || it.i >= len(it.leaves) {
+		return nil
+	}
+	return it.leaves[it.i].path
+}
+
+// Values returns the values of the current node.
+func (it *LeafIterator) Values() []interface{} {
+	if it.i < 0 || it.i >= len(it.leaves) {
+		return nil
+	}
+	return it.leaves[it.i].values
+}
@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package qs
+
+import "iter"
+
+// Leaves returns an iterator over the path and values of every node that
+// holds values, in the same order as Walk. The iterator works on a snapshot
+// taken when iteration starts, so the QS may be modified inside the loop.
+//
+//	for path, values := range q.Leaves() {
+//		fmt.Println(path, values)
+//	}
+func (q *QS) Leaves() iter.Seq2[[]string, []interface{}] {
+	return func(yield func([]string, []interface{}) bool) {
+		for _, l := range q.leaves() {
+			if !yield(l.path, l.values) {
+				return
+			}
+		}
+	}
+}
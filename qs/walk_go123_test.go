@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package qs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQS_Leaves(t *testing.T) {
+	q, err := New("j=true&a[g]=h&a[g]=i&a[b][c]=1&z=2")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	paths := make([][]string, 0)
+	values := make([][]interface{}, 0)
+	for path, vals := range q.Leaves() {
+		if path[0] == "z" {
+			break
+		}
+		paths = append(paths, path)
+		values = append(values, vals)
+	}
+
+	wantPaths := [][]string{{"j"}, {"a", "g"}, {"a", "b", "c"}}
+	wantValues := [][]interface{}{{"true"}, {"h", "i"}, {"1"}}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("QS.Leaves() paths = %v, want %v", paths, wantPaths)
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("QS.Leaves() values = %v, want %v", values, wantValues)
+	}
+}
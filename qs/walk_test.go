@@ -0,0 +1,141 @@
+package qs
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQS_Walk(t *testing.T) {
+	query := "j=true&a[g]=h&a[g]=i&a[b]=123&a[b][c][d]=c&d[]=1.05"
+
+	type visit struct {
+		path   string
+		values []interface{}
+	}
+
+	tests := []struct {
+		name    string
+		fn      func(path []string) error
+		want    []visit
+		wantErr error
+	}{
+		{
+			name: "Visit all",
+			fn:   func(path []string) error { return nil },
+			want: []visit{
+				{"j", []interface{}{"true"}},
+				{"a", []interface{}{}},
+				{"a.g", []interface{}{"h", "i"}},
+				{"a.b", []interface{}{"123"}},
+				{"a.b.c", []interface{}{}},
+				{"a.b.c.d", []interface{}{"c"}},
+				{"d", []interface{}{"1.05"}},
+			},
+		},
+		{
+			name: "Skip node",
+			fn: func(path []string) error {
+				if strings.Join(path, ".") == "a.b" {
+					return SkipNode
+				}
+				return nil
+			},
+			want: []visit{
+				{"j", []interface{}{"true"}},
+				{"a", []interface{}{}},
+				{"a.g", []interface{}{"h", "i"}},
+				{"a.b", []interface{}{"123"}},
+				{"d", []interface{}{"1.05"}},
+			},
+		},
+		{
+			name: "Skip all",
+			fn: func(path []string) error {
+				if strings.Join(path, ".") == "a.g" {
+					return SkipAll
+				}
+				return nil
+			},
+			want: []visit{
+				{"j", []interface{}{"true"}},
+				{"a", []interface{}{}},
+				{"a.g", []interface{}{"h", "i"}},
+			},
+		},
+		{
+			name: "Error",
+			fn: func(path []string) error {
+				if path[0] == "a" {
+					return errTestWalk
+				}
+				return nil
+			},
+			want: []visit{
+				{"j", []interface{}{"true"}},
+				{"a", []interface{}{}},
+			},
+			wantErr: errTestWalk,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(query)
+			if err != nil {
+				t.Fatalf("NewQS failed with err, %s", err)
+			}
+
+			got := make([]visit, 0)
+			err = q.Walk(func(path []string, values []interface{}) error {
+				got = append(got, visit{strings.Join(path, "."), values})
+				return tt.fn(path)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("QS.Walk() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QS.Walk() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var errTestWalk = errors.New("walk failed")
+
+func TestQS_Iter(t *testing.T) {
+	q, err := New("j=true&a[g]=h&a[g]=i&a[b][c]=1")
+	if err != nil {
+		t.Fatalf("NewQS failed with err, %s", err)
+	}
+
+	it := q.Iter()
+	if it.Path() != nil || it.Values() != nil {
+		t.Errorf("LeafIterator returned a node before Next was called")
+	}
+
+	// Modifying the QS while iterating must not deadlock or affect the
+	// iterator.
+	paths := make([][]string, 0)
+	values := make([][]interface{}, 0)
+	for it.Next() {
+		paths = append(paths, it.Path())
+		values = append(values, it.Values())
+		q.Delete(it.Path()...)
+	}
+
+	wantPaths := [][]string{{"j"}, {"a", "g"}, {"a", "b", "c"}}
+	wantValues := [][]interface{}{{"true"}, {"h", "i"}, {"1"}}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("LeafIterator paths = %v, want %v", paths, wantPaths)
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("LeafIterator values = %v, want %v", values, wantValues)
+	}
+	if it.Next() {
+		t.Errorf("LeafIterator.Next() = true after the last node")
+	}
+	if got := q.String(); got != "" {
+		t.Errorf("QS.String() = %v, want empty string", got)
+	}
+}